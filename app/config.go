@@ -1,15 +1,34 @@
 package app
 
 import (
+	"fmt"
 	yaml "gopkg.in/yaml.v3"
 	"io/ioutil"
+	"time"
+	"website-monitor/maintenance"
 	"website-monitor/monitors"
 )
 
+const DefaultStateFile = "config/state.json"
+
+// HostLimits limits the load put on each origin, shared by all monitors
+// pointing at it.
+type HostLimits struct {
+	MaxConcurrent int           `yaml:"max_concurrent"`
+	MinSpacing    time.Duration `yaml:"min_spacing"`
+}
+
 type Config struct {
-	LogLevel string              `yaml:"loglevel"`
-	Default  *monitors.Monitor   `yaml:"defaults"`
-	Monitors []*monitors.Monitor `yaml:"monitors"`
+	LogLevel        string                 `yaml:"loglevel"`
+	Startup         monitors.StartupPolicy `yaml:"startup"`
+	StateFile       string                 `yaml:"state_file"`
+	Hosts           HostLimits             `yaml:"hosts"`
+	Workers         int                    `yaml:"workers"`
+	ShutdownTimeout time.Duration          `yaml:"shutdown_timeout"`
+	ReloadInterval  time.Duration          `yaml:"reload_interval"`
+	Default         *monitors.Monitor      `yaml:"defaults"`
+	Maintenance     maintenance.Windows    `yaml:"maintenance"`
+	Monitors        []*monitors.Monitor    `yaml:"monitors"`
 }
 
 func (c *Config) LoadConfigFromFile(filename string) error {
@@ -27,7 +46,40 @@ func (c *Config) LoadConfig(configData []byte) error {
 		return err
 	}
 
+	switch c.Startup {
+	case "", monitors.ImmediateStartup, monitors.StaggeredStartup, monitors.ResumeStartup:
+	default:
+		return fmt.Errorf("unsupported startup policy '%s'", c.Startup)
+	}
+
+	if c.ReloadInterval < 0 {
+		return fmt.Errorf("invalid reload interval: %s", c.ReloadInterval)
+	}
+
+	if c.Workers < 0 {
+		return fmt.Errorf("invalid number of workers: %d", c.Workers)
+	}
+
+	if c.Startup == monitors.ResumeStartup && c.StateFile == "" {
+		c.StateFile = DefaultStateFile
+	}
+
+	if c.Default != nil {
+		// Defaults have no url or steps, what depends on the type is
+		// validated in each monitor once the defaults are merged.
+		def := *c.Default
+		def.Type = ""
+		if err := def.Validate(); err != nil {
+			return err
+		}
+	}
+
 	for _, chk := range c.Monitors {
+		// Steps of a transaction are relative to its url, which is the
+		// first step's by default.
+		if chk.Url == "" && len(chk.Steps) > 0 {
+			chk.Url = chk.Steps[0].Url
+		}
 		if chk.DisplayUrl == "" {
 			chk.DisplayUrl = chk.Url
 		}
@@ -37,6 +89,7 @@ func (c *Config) LoadConfig(configData []byte) error {
 		if _, ok := chk.Headers["Referer"]; !ok {
 			chk.Headers["Referer"] = chk.DisplayUrl
 		}
+		chk.Maintenance = append(chk.Maintenance, c.Maintenance...)
 		if c.Default != nil {
 			if c.Default.Notifiers != nil && chk.Notifiers == nil {
 				chk.Notifiers = c.Default.Notifiers
@@ -44,12 +97,47 @@ func (c *Config) LoadConfig(configData []byte) error {
 			if c.Default.Scheduler != nil && chk.Scheduler == nil {
 				chk.Scheduler = c.Default.Scheduler
 			}
+			if c.Default.Scheduler != nil && chk.Scheduler.Timezone == "" && c.Default.Scheduler.Timezone != "" {
+				if err := chk.Scheduler.SetTimezone(c.Default.Scheduler.Timezone); err != nil {
+					return err
+				}
+			}
 			if c.Default.Type != "" && chk.Type == "" {
 				chk.Type = c.Default.Type
 			}
-			if c.Default.ExpectedStatusCode != 0 && chk.ExpectedStatusCode == 0 {
+			if c.Default.ExpectedStatusCode != nil && chk.ExpectedStatusCode == nil {
 				chk.ExpectedStatusCode = c.Default.ExpectedStatusCode
 			}
+			if c.Default.Timeout != 0 && chk.Timeout == 0 {
+				chk.Timeout = c.Default.Timeout
+			}
+			if c.Default.ConnectTimeout != 0 && chk.ConnectTimeout == 0 {
+				chk.ConnectTimeout = c.Default.ConnectTimeout
+			}
+			if c.Default.Retries != 0 && chk.Retries == 0 {
+				chk.Retries = c.Default.Retries
+			}
+			if c.Default.RetryBackoff != 0 && chk.RetryBackoff == 0 {
+				chk.RetryBackoff = c.Default.RetryBackoff
+			}
+			if c.Default.RetryStatusCodes != nil && chk.RetryStatusCodes == nil {
+				chk.RetryStatusCodes = c.Default.RetryStatusCodes
+			}
+			if c.Default.FollowRedirects != nil && chk.FollowRedirects == nil {
+				chk.FollowRedirects = c.Default.FollowRedirects
+			}
+			if c.Default.TLS != nil && chk.TLS == nil {
+				chk.TLS = c.Default.TLS
+			}
+			if c.Default.Proxy != "" && chk.Proxy == "" {
+				chk.Proxy = c.Default.Proxy
+			}
+			if c.Default.Auth != nil && chk.Auth == nil {
+				chk.Auth = c.Default.Auth
+			}
+			if c.Default.Resolver != "" && chk.Resolver == "" {
+				chk.Resolver = c.Default.Resolver
+			}
 
 			for k, v := range c.Default.Headers {
 				if _, ok := chk.Headers[k]; !ok {
@@ -58,6 +146,9 @@ func (c *Config) LoadConfig(configData []byte) error {
 			}
 
 		}
+		if err := chk.Validate(); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -4,8 +4,10 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"testing"
+	"time"
 	"website-monitor/app"
 	"website-monitor/content_checkers"
+	"website-monitor/maintenance"
 	"website-monitor/monitors"
 	"website-monitor/notifiers"
 	"website-monitor/scheduler"
@@ -29,6 +31,18 @@ func PushSaferNotifierWithoutError(name string, options map[string]string) *noti
 	return s
 }
 
+func redirectLimit(max int) *monitors.RedirectLimit {
+	rl := monitors.RedirectLimit(max)
+
+	return &rl
+}
+
+func cookieChecker(name, cookie string) *content_checkers.CookieChecker {
+	c, _ := content_checkers.NewCookieChecker(name, cookie, content_checkers.ExistsMatch, "")
+
+	return c
+}
+
 func TestParseConfig(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -108,7 +122,7 @@ monitors:
 						Url:                "http://example.com/test",
 						DisplayUrl:         "http://example.com/test",
 						Type:               "http",
-						ExpectedStatusCode: 200,
+						ExpectedStatusCode: content_checkers.NewStatusCodes(200),
 						ContentChecks: []content_checkers.ContentCheckerHolder{
 							{
 								content_checkers.NewRegexChecker("A monitor for regex", "Some monitored text", true),
@@ -123,6 +137,358 @@ monitors:
 				},
 			},
 		},
+		{
+			name: "cron schedule",
+			data: []byte(`
+monitors:
+  - name: "cron"
+    schedule:
+      cron: "*/5 * * * 1-5"
+      interval_variation_percentage: 10
+`),
+			expected: &app.Config{
+				Monitors: []*monitors.Monitor{
+					{
+						Name:      "cron",
+						Headers:   map[string]string{"Referer": ""},
+						Scheduler: SchedulerWithoutError("0;10;;;*/5 * * * 1-5"),
+					},
+				},
+			},
+		},
+		{
+			name: "timezone from defaults",
+			data: []byte(`
+defaults:
+  schedule:
+    interval: 60s
+    interval_variation_percentage: 0
+    timezone: Europe/Oslo
+monitors:
+  - name: "default schedule"
+  - name: "own schedule"
+    schedule:
+      interval: 300s
+      interval_variation_percentage: 0
+      hours: 9-16
+  - name: "own timezone"
+    schedule:
+      interval: 300s
+      interval_variation_percentage: 0
+      timezone: America/New_York
+`),
+			expected: &app.Config{
+				Default: &monitors.Monitor{
+					Scheduler: SchedulerWithoutError("60;0;;;;Europe/Oslo"),
+				},
+				Monitors: []*monitors.Monitor{
+					{
+						Name:      "default schedule",
+						Headers:   map[string]string{"Referer": ""},
+						Scheduler: SchedulerWithoutError("60;0;;;;Europe/Oslo"),
+					},
+					{
+						Name:      "own schedule",
+						Headers:   map[string]string{"Referer": ""},
+						Scheduler: SchedulerWithoutError("300;0;;9,10,11,12,13,14,15,16;;Europe/Oslo"),
+					},
+					{
+						Name:      "own timezone",
+						Headers:   map[string]string{"Referer": ""},
+						Scheduler: SchedulerWithoutError("300;0;;;;America/New_York"),
+					},
+				},
+			},
+		},
+		{
+			name: "global and monitor maintenance",
+			data: []byte(`
+maintenance:
+  - name: "Weekly backup"
+    window: "sun 02:00-04:00"
+    mode: mute_notifications
+monitors:
+  - name: "maintenance"
+    maintenance:
+      - name: "Upgrade"
+        from: "2021-03-01 22:00"
+        to: "2021-03-02 02:00"
+`),
+			expected: &app.Config{
+				Maintenance: maintenance.Windows{
+					{
+						Name:      "Weekly backup",
+						Mode:      maintenance.MuteNotificationsMode,
+						Recurring: &scheduler.Window{Days: scheduler.Days{0}, Start: 2 * 60, End: 4 * 60},
+					},
+				},
+				Monitors: []*monitors.Monitor{
+					{
+						Name:    "maintenance",
+						Headers: map[string]string{"Referer": ""},
+						Maintenance: maintenance.Windows{
+							{
+								Name: "Upgrade",
+								Mode: maintenance.SkipChecksMode,
+								From: time.Date(2021, 03, 01, 22, 00, 00, 00, time.UTC),
+								To:   time.Date(2021, 03, 02, 02, 00, 00, 00, time.UTC),
+							},
+							{
+								Name:      "Weekly backup",
+								Mode:      maintenance.MuteNotificationsMode,
+								Recurring: &scheduler.Window{Days: scheduler.Days{0}, Start: 2 * 60, End: 4 * 60},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "resume startup uses default state file",
+			data: []byte(`
+startup: resume
+`),
+			expected: &app.Config{
+				Startup:   monitors.ResumeStartup,
+				StateFile: app.DefaultStateFile,
+			},
+		},
+		{
+			name: "staggered startup with state file",
+			data: []byte(`
+startup: staggered
+state_file: /tmp/state.json
+`),
+			expected: &app.Config{
+				Startup:   monitors.StaggeredStartup,
+				StateFile: "/tmp/state.json",
+			},
+		},
+		{
+			name: "host limits",
+			data: []byte(`
+hosts:
+  max_concurrent: 2
+  min_spacing: 1500ms
+`),
+			expected: &app.Config{
+				Hosts: app.HostLimits{
+					MaxConcurrent: 2,
+					MinSpacing:    1500 * time.Millisecond,
+				},
+			},
+		},
+		{
+			name: "request method and body",
+			data: []byte(`
+monitors:
+  - name: "graphql"
+    url: "https://example.com/graphql"
+    method: post
+    json_body:
+      query: "{ status }"
+      variables:
+        id: 1
+  - name: "search"
+    url: "https://example.com/search"
+    method: get
+    form:
+      q: "status"
+`),
+			expected: &app.Config{
+				Monitors: []*monitors.Monitor{
+					{
+						Name:       "graphql",
+						Url:        "https://example.com/graphql",
+						DisplayUrl: "https://example.com/graphql",
+						Headers:    map[string]string{"Referer": "https://example.com/graphql"},
+						Method:     "post",
+						JsonBody: map[string]interface{}{
+							"query":     "{ status }",
+							"variables": map[string]interface{}{"id": 1},
+						},
+					},
+					{
+						Name:       "search",
+						Url:        "https://example.com/search",
+						DisplayUrl: "https://example.com/search",
+						Headers:    map[string]string{"Referer": "https://example.com/search"},
+						Method:     "get",
+						Form:       map[string]string{"q": "status"},
+					},
+				},
+			},
+		},
+		{
+			name: "timeouts and retries from defaults",
+			data: []byte(`
+defaults:
+  timeout: 10s
+  connect_timeout: 2s
+  retries: 2
+  retry_backoff: 500ms
+  retry_status_codes: [502, 503]
+monitors:
+  - name: "default"
+  - name: "own"
+    timeout: 30s
+    retries: 1
+`),
+			expected: &app.Config{
+				Default: &monitors.Monitor{
+					Timeout:          10 * time.Second,
+					ConnectTimeout:   2 * time.Second,
+					Retries:          2,
+					RetryBackoff:     500 * time.Millisecond,
+					RetryStatusCodes: []int{502, 503},
+				},
+				Monitors: []*monitors.Monitor{
+					{
+						Name:             "default",
+						Headers:          map[string]string{"Referer": ""},
+						Timeout:          10 * time.Second,
+						ConnectTimeout:   2 * time.Second,
+						Retries:          2,
+						RetryBackoff:     500 * time.Millisecond,
+						RetryStatusCodes: []int{502, 503},
+					},
+					{
+						Name:             "own",
+						Headers:          map[string]string{"Referer": ""},
+						Timeout:          30 * time.Second,
+						ConnectTimeout:   2 * time.Second,
+						Retries:          1,
+						RetryBackoff:     500 * time.Millisecond,
+						RetryStatusCodes: []int{502, 503},
+					},
+				},
+			},
+		},
+		{
+			name: "expected status codes",
+			data: []byte(`
+defaults:
+  expected_status_code: [200, 304]
+monitors:
+  - name: "default"
+  - name: "own"
+    expected_status_code: "2xx"
+`),
+			expected: &app.Config{
+				Default: &monitors.Monitor{
+					ExpectedStatusCode: content_checkers.NewStatusCodes(200, 304),
+				},
+				Monitors: []*monitors.Monitor{
+					{
+						Name:               "default",
+						Headers:            map[string]string{"Referer": ""},
+						ExpectedStatusCode: content_checkers.NewStatusCodes(200, 304),
+					},
+					{
+						Name:               "own",
+						Headers:            map[string]string{"Referer": ""},
+						ExpectedStatusCode: content_checkers.StatusCodes{{Min: 200, Max: 299}},
+					},
+				},
+			},
+		},
+		{
+			name: "follow redirects",
+			data: []byte(`
+defaults:
+  follow_redirects: false
+monitors:
+  - name: "default"
+  - name: "limited"
+    follow_redirects: 3
+  - name: "all"
+    follow_redirects: true
+`),
+			expected: &app.Config{
+				Default: &monitors.Monitor{
+					FollowRedirects: redirectLimit(0),
+				},
+				Monitors: []*monitors.Monitor{
+					{
+						Name:            "default",
+						Headers:         map[string]string{"Referer": ""},
+						FollowRedirects: redirectLimit(0),
+					},
+					{
+						Name:            "limited",
+						Headers:         map[string]string{"Referer": ""},
+						FollowRedirects: redirectLimit(3),
+					},
+					{
+						Name:            "all",
+						Headers:         map[string]string{"Referer": ""},
+						FollowRedirects: redirectLimit(monitors.DefaultMaxRedirects),
+					},
+				},
+			},
+		},
+		{
+			name: "transaction steps",
+			data: []byte(`
+monitors:
+  - name: "login"
+    type: transaction
+    steps:
+      - name: "login page"
+        url: "https://example.com/login"
+        capture:
+          - name: csrf
+            type: xpath
+            path: "//input[@name='csrf']/@value"
+      - name: "login"
+        url: "/login"
+        form:
+          csrf: "${csrf}"
+        expected_status_code: 302
+        checks:
+          - name: "session"
+            type: cookie
+            path: session
+`),
+			expected: &app.Config{
+				Monitors: []*monitors.Monitor{
+					{
+						Name:       "login",
+						Url:        "https://example.com/login",
+						DisplayUrl: "https://example.com/login",
+						Type:       monitors.TransactionMonitorType,
+						Headers:    map[string]string{"Referer": "https://example.com/login"},
+						Steps: []monitors.Step{
+							{
+								Name: "login page",
+								Url:  "https://example.com/login",
+								Captures: []monitors.Capture{
+									{Name: "csrf", Type: monitors.XPathCapture, Path: "//input[@name='csrf']/@value"},
+								},
+							},
+							{
+								Name:               "login",
+								Url:                "/login",
+								Form:               map[string]string{"csrf": "${csrf}"},
+								ExpectedStatusCode: content_checkers.NewStatusCodes(302),
+								ContentChecks: []content_checkers.ContentCheckerHolder{
+									{cookieChecker("session", "session")},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "reload interval",
+			data: []byte(`
+reload_interval: 30s
+`),
+			expected: &app.Config{
+				ReloadInterval: 30 * time.Second,
+			},
+		},
 		{
 			name: "Defaults",
 			data: []byte(`
@@ -159,7 +525,7 @@ monitors:
 				LogLevel: "debug",
 				Default: &monitors.Monitor{
 					Type: "http",
-					ExpectedStatusCode: 200,
+					ExpectedStatusCode: content_checkers.NewStatusCodes(200),
 					Scheduler: SchedulerWithoutError("300;20;1,2,3,4,5;7,8,9,10,11,12"),
 					Headers: map[string]string{
 						"User-Agent": "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.16; rv:85.0) Gecko/20100101 Firefox/85.0",
@@ -179,7 +545,7 @@ monitors:
 						Url:                "http://example.com/test",
 						DisplayUrl:         "http://example.com/test",
 						Type:               "http",
-						ExpectedStatusCode: 200,
+						ExpectedStatusCode: content_checkers.NewStatusCodes(200),
 						ContentChecks: []content_checkers.ContentCheckerHolder{
 							{
 								content_checkers.NewRegexChecker("A monitor for regex", "Some monitored text", true),
@@ -222,3 +588,53 @@ monitors:
 		})
 	}
 }
+
+func TestLoadConfig_ValidatesWithDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  bool
+	}{
+		{
+			name: "type from defaults",
+			data: `
+defaults:
+  type: tcp
+monitors:
+  - name: "relay"
+    url: "relay.example:25"
+`,
+		},
+		{
+			name: "address invalid for the type from defaults",
+			data: `
+defaults:
+  type: tcp
+monitors:
+  - name: "relay"
+    url: "relay.example"
+`,
+			err: true,
+		},
+		{
+			name: "record_type invalid for the type from defaults",
+			data: `
+defaults:
+  type: dns
+monitors:
+  - name: "records"
+    url: "example.com"
+    record_type: SOA
+`,
+			err: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &app.Config{}
+			if err := cfg.LoadConfig([]byte(test.data)); (err != nil) != test.err {
+				t.Errorf("got err %v, expected err %t", err, test.err)
+			}
+		})
+	}
+}
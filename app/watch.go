@@ -0,0 +1,39 @@
+package app
+
+import (
+	"context"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// WatchFile calls onChange whenever the modification time of filename
+// changes, looking every interval until ctx is done.
+func WatchFile(ctx context.Context, filename string, interval time.Duration, onChange func()) {
+	var modTime time.Time
+	if fi, err := os.Stat(filename); err == nil {
+		modTime = fi.ModTime()
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			fi, err := os.Stat(filename)
+			if err != nil {
+				log.Warnf("Error while watching %s: %s", filename, err)
+				continue
+			}
+			if fi.ModTime().Equal(modTime) {
+				continue
+			}
+			modTime = fi.ModTime()
+			onChange()
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package auth adds authentication to the requests of monitors.
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+type Type string
+
+const (
+	BasicType  Type = "basic"
+	BearerType Type = "bearer"
+	OAuth2Type Type = "oauth2"
+)
+
+// Config is the auth block of a monitor. Secrets can be given directly, or
+// read from an environment variable or a file on each check, so they don't
+// have to be in the config and can be rotated.
+type Config struct {
+	Type Type `yaml:"type"`
+
+	// basic
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
+	PasswordEnv  string `yaml:"password_env"`
+	PasswordFile string `yaml:"password_file"`
+
+	// bearer
+	Token     string `yaml:"token"`
+	TokenEnv  string `yaml:"token_env"`
+	TokenFile string `yaml:"token_file"`
+
+	// oauth2 client credentials
+	TokenURL         string   `yaml:"token_url"`
+	ClientID         string   `yaml:"client_id"`
+	ClientSecret     string   `yaml:"client_secret"`
+	ClientSecretEnv  string   `yaml:"client_secret_env"`
+	ClientSecretFile string   `yaml:"client_secret_file"`
+	Scopes           []string `yaml:"scopes"`
+}
+
+func (c *Config) Validate() error {
+	switch c.Type {
+	case BasicType:
+		if c.Username == "" {
+			return fmt.Errorf("basic auth needs a username")
+		}
+		return validateSecret("password", c.Password, c.PasswordEnv, c.PasswordFile, true)
+	case BearerType:
+		return validateSecret("token", c.Token, c.TokenEnv, c.TokenFile, false)
+	case OAuth2Type:
+		if c.TokenURL == "" || c.ClientID == "" {
+			return fmt.Errorf("oauth2 auth needs a token_url and client_id")
+		}
+		return validateSecret("client_secret", c.ClientSecret, c.ClientSecretEnv, c.ClientSecretFile, false)
+	default:
+		return fmt.Errorf("unknown auth type '%s', expected basic, bearer or oauth2", c.Type)
+	}
+}
+
+// validateSecret checks that a secret is given in exactly one way, or not at
+// all when it's optional.
+func validateSecret(name, value, env, file string, optional bool) error {
+	n := 0
+	for _, s := range []string{value, env, file} {
+		if s != "" {
+			n++
+		}
+	}
+	if n > 1 {
+		return fmt.Errorf("only one of %s, %s_env and %s_file can be set", name, name, name)
+	}
+	if n == 0 && !optional {
+		return fmt.Errorf("auth needs one of %s, %s_env or %s_file", name, name, name)
+	}
+
+	return nil
+}
+
+// secret returns value, or reads the secret from the environment variable or
+// file.
+func secret(value, env, file string) (string, error) {
+	switch {
+	case env != "":
+		s, ok := os.LookupEnv(env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", env)
+		}
+		return s, nil
+	case file != "":
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("error reading secret: %v", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return value, nil
+	}
+}
+
+// Authorization returns the value of the Authorization header. OAuth2 tokens
+// are requested with client, and cached until shortly before they expire.
+func (c *Config) Authorization(ctx context.Context, client *http.Client) (string, error) {
+	switch c.Type {
+	case BasicType:
+		password, err := secret(c.Password, c.PasswordEnv, c.PasswordFile)
+		if err != nil {
+			return "", err
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.Username+":"+password)), nil
+	case BearerType:
+		token, err := secret(c.Token, c.TokenEnv, c.TokenFile)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	case OAuth2Type:
+		clientSecret, err := secret(c.ClientSecret, c.ClientSecretEnv, c.ClientSecretFile)
+		if err != nil {
+			return "", err
+		}
+		return tokens.authorization(ctx, client, c.clientCredentials(clientSecret))
+	default:
+		return "", fmt.Errorf("unknown auth type '%s'", c.Type)
+	}
+}
+
+// Invalidate drops the cached oauth2 token if its Authorization header is
+// authorization, so the next call to Authorization requests a new one. It's
+// called when the server rejected the header, tokens can be revoked before
+// they expire. Other types aren't cached.
+func (c *Config) Invalidate(authorization string) {
+	if c.Type != OAuth2Type {
+		return
+	}
+
+	clientSecret, err := secret(c.ClientSecret, c.ClientSecretEnv, c.ClientSecretFile)
+	if err != nil {
+		return
+	}
+	tokens.invalidate(c.clientCredentials(clientSecret), authorization)
+}
+
+func (c *Config) clientCredentials(clientSecret string) clientCredentials {
+	return clientCredentials{
+		tokenURL:     c.TokenURL,
+		clientID:     c.ClientID,
+		clientSecret: clientSecret,
+		scopes:       strings.Join(c.Scopes, " "),
+	}
+}
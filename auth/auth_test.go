@@ -0,0 +1,169 @@
+package auth_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"website-monitor/auth"
+)
+
+func TestConfig_Authorization(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	if err := ioutil.WriteFile(file, []byte("file-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("AUTH_TEST_SECRET", "env-secret")
+	defer os.Unsetenv("AUTH_TEST_SECRET")
+
+	tests := []struct {
+		name     string
+		config   auth.Config
+		expected string
+		err      bool
+	}{
+		{"basic", auth.Config{Type: auth.BasicType, Username: "user", Password: "pass"}, "Basic dXNlcjpwYXNz", false},
+		{"basic env", auth.Config{Type: auth.BasicType, Username: "user", PasswordEnv: "AUTH_TEST_SECRET"}, "Basic dXNlcjplbnYtc2VjcmV0", false},
+		{"bearer", auth.Config{Type: auth.BearerType, Token: "token"}, "Bearer token", false},
+		{"bearer env", auth.Config{Type: auth.BearerType, TokenEnv: "AUTH_TEST_SECRET"}, "Bearer env-secret", false},
+		{"bearer file", auth.Config{Type: auth.BearerType, TokenFile: file}, "Bearer file-token", false},
+		{"missing env", auth.Config{Type: auth.BearerType, TokenEnv: "AUTH_TEST_MISSING"}, "", true},
+		{"missing file", auth.Config{Type: auth.BearerType, TokenFile: "missing"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.Authorization(context.Background(), http.DefaultClient)
+			if (err != nil) != tt.err {
+				t.Fatalf("got err %v, expected err %t", err, tt.err)
+			}
+			if got != tt.expected {
+				t.Errorf("got '%s', expected '%s'", got, tt.expected)
+			}
+		})
+	}
+}
+
+func tokenServer(t *testing.T, expiresIn int64, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		id, secret, _ := r.BasicAuth()
+		if r.FormValue("grant_type") != "client_credentials" || id != "id" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("scope") != "read write" {
+			t.Errorf("got scope '%s', expected 'read write'", r.FormValue("scope"))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "token",
+			"token_type":   "bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+}
+
+func TestConfig_AuthorizationOAuth2(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresIn int64
+		secret    string
+		requests  int
+		err       bool
+	}{
+		{"cached", 3600, "secret", 1, false},
+		// expires within the margin, so it's requested for every check
+		{"refreshed", 30, "secret", 2, false},
+		{"invalid secret", 3600, "invalid", 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			ts := tokenServer(t, tt.expiresIn, &requests)
+			defer ts.Close()
+
+			config := auth.Config{
+				Type:         auth.OAuth2Type,
+				TokenURL:     ts.URL,
+				ClientID:     "id",
+				ClientSecret: tt.secret,
+				Scopes:       []string{"read", "write"},
+			}
+			for i := 0; i < 2; i++ {
+				got, err := config.Authorization(context.Background(), ts.Client())
+				if (err != nil) != tt.err {
+					t.Fatalf("got err %v, expected err %t", err, tt.err)
+				}
+				if !tt.err && got != "Bearer token" {
+					t.Errorf("got '%s', expected 'Bearer token'", got)
+				}
+			}
+			if requests != tt.requests {
+				t.Errorf("got %d token requests, expected %d", requests, tt.requests)
+			}
+		})
+	}
+}
+
+func TestConfig_Invalidate(t *testing.T) {
+	var requests int
+	ts := tokenServer(t, 3600, &requests)
+	defer ts.Close()
+
+	config := auth.Config{
+		Type:         auth.OAuth2Type,
+		TokenURL:     ts.URL,
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+	}
+	got, err := config.Authorization(context.Background(), ts.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A header which isn't the cached one keeps the token.
+	config.Invalidate("Bearer other")
+	if _, err := config.Authorization(context.Background(), ts.Client()); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("got %d token requests, expected the token to be kept", requests)
+	}
+
+	config.Invalidate(got)
+	if _, err := config.Authorization(context.Background(), ts.Client()); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("got %d token requests, expected a new token to be requested", requests)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config auth.Config
+		err    bool
+	}{
+		{"basic", auth.Config{Type: auth.BasicType, Username: "user", Password: "pass"}, false},
+		{"basic without password", auth.Config{Type: auth.BasicType, Username: "user"}, false},
+		{"basic without username", auth.Config{Type: auth.BasicType, Password: "pass"}, true},
+		{"bearer", auth.Config{Type: auth.BearerType, TokenFile: "token"}, false},
+		{"bearer without token", auth.Config{Type: auth.BearerType}, true},
+		{"bearer with two tokens", auth.Config{Type: auth.BearerType, Token: "token", TokenEnv: "TOKEN"}, true},
+		{"oauth2", auth.Config{Type: auth.OAuth2Type, TokenURL: "https://auth", ClientID: "id", ClientSecretEnv: "SECRET"}, false},
+		{"oauth2 without token url", auth.Config{Type: auth.OAuth2Type, ClientID: "id", ClientSecret: "secret"}, true},
+		{"unknown", auth.Config{Type: "digest"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.err {
+				t.Errorf("got err %v, expected err %t", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryMargin is how long before it expires a token is refreshed, so it
+// doesn't expire during a check.
+const expiryMargin = 30 * time.Second
+
+// defaultExpiry is used when the token response has no expires_in.
+const defaultExpiry = time.Hour
+
+// clientCredentials identifies a cached token.
+type clientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       string
+}
+
+type token struct {
+	authorization string
+	expiresAt     time.Time
+}
+
+// tokenCache shares tokens between all monitors with the same credentials.
+type tokenCache struct {
+	mu     sync.Mutex
+	tokens map[clientCredentials]token
+}
+
+var tokens = &tokenCache{tokens: make(map[clientCredentials]token)}
+
+func (tc *tokenCache) authorization(ctx context.Context, client *http.Client, cc clientCredentials) (string, error) {
+	tc.mu.Lock()
+	t, ok := tc.tokens[cc]
+	tc.mu.Unlock()
+	if ok && time.Now().Before(t.expiresAt) {
+		return t.authorization, nil
+	}
+
+	// Requested without holding the lock, monitors checked at the same time
+	// may both request a token.
+	t, err := requestToken(ctx, client, cc)
+	if err != nil {
+		return "", err
+	}
+
+	tc.mu.Lock()
+	tc.tokens[cc] = t
+	tc.mu.Unlock()
+
+	return t.authorization, nil
+}
+
+// invalidate drops the token of cc if it's still the one with the header
+// authorization, and not one requested since.
+func (tc *tokenCache) invalidate(cc clientCredentials, authorization string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if t, ok := tc.tokens[cc]; ok && t.authorization == authorization {
+		delete(tc.tokens, cc)
+	}
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// requestToken requests a token with the client credentials grant, RFC 6749
+// section 4.4.
+func requestToken(ctx context.Context, client *http.Client, cc clientCredentials) (token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if cc.scopes != "" {
+		form.Set("scope", cc.scopes)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cc.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(cc.clientID), url.QueryEscape(cc.clientSecret))
+
+	requested := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return token{}, fmt.Errorf("error requesting oauth2 token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return token{}, fmt.Errorf("error requesting oauth2 token: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return token{}, fmt.Errorf("error requesting oauth2 token: status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tr tokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return token{}, fmt.Errorf("error parsing oauth2 token: %v", err)
+	}
+	if tr.AccessToken == "" {
+		return token{}, fmt.Errorf("no access_token in oauth2 token response")
+	}
+
+	expiresIn := defaultExpiry
+	if tr.ExpiresIn > 0 {
+		expiresIn = time.Duration(tr.ExpiresIn) * time.Second
+	}
+	// Token types are case insensitive, but servers don't always accept
+	// "bearer".
+	tokenType := tr.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+
+	return token{
+		authorization: tokenType + " " + tr.AccessToken,
+		expiresAt:     requested.Add(expiresIn - expiryMargin),
+	}, nil
+}
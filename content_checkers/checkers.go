@@ -9,10 +9,20 @@ import (
 type CheckType string
 
 const (
-	RegexCheckType CheckType = "regex"
-	HtmlXpathType  CheckType = "html_xpath"
-	JsonPathType   CheckType = "json_path"
-	HtmlRenderType CheckType = "html_render"
+	RegexCheckType   CheckType = "regex"
+	HtmlXpathType    CheckType = "html_xpath"
+	JsonPathType     CheckType = "json_path"
+	HtmlRenderType   CheckType = "html_render"
+	RedirectType     CheckType = "redirect"
+	HeaderType       CheckType = "header"
+	CookieType       CheckType = "cookie"
+	ResponseTimeType CheckType = "response_time"
+	TLSExpiryType    CheckType = "tls_expiry"
+	TLSHostnameType  CheckType = "tls_hostname"
+	TLSChainType     CheckType = "tls_chain"
+	TLSVersionType   CheckType = "tls_version"
+	DNSRecordsType   CheckType = "dns_records"
+	ReachabilityType CheckType = "reachability"
 )
 
 type ContentChecker interface {
@@ -33,6 +43,7 @@ func (cch *ContentCheckerHolder) UnmarshalYAML(unmarshal func(interface{}) error
 		Path       string    `yaml:"path"`
 		Value      string    `yaml:"value"`
 		IsExpected bool      `yaml:"is_expected"`
+		Match      MatchMode `yaml:"match"`
 	}
 
 	var tmp alias
@@ -49,6 +60,58 @@ func (cch *ContentCheckerHolder) UnmarshalYAML(unmarshal func(interface{}) error
 		cch.ContentChecker = NewJsonPathChecker(tmp.Name, tmp.Path, tmp.Value, tmp.IsExpected)
 	case HtmlRenderType:
 		cch.ContentChecker = NewHtmlRenderSelectorChecker(tmp.Name, tmp.Path, tmp.Value, tmp.IsExpected)
+	case RedirectType:
+		rc, err := NewRedirectChecker(tmp.Name, tmp.Path, tmp.Value, tmp.IsExpected)
+		if err != nil {
+			return err
+		}
+		cch.ContentChecker = rc
+	case HeaderType:
+		hc, err := NewHeaderChecker(tmp.Name, tmp.Path, tmp.Match, tmp.Value)
+		if err != nil {
+			return err
+		}
+		cch.ContentChecker = hc
+	case CookieType:
+		cc, err := NewCookieChecker(tmp.Name, tmp.Path, tmp.Match, tmp.Value)
+		if err != nil {
+			return err
+		}
+		cch.ContentChecker = cc
+	case ResponseTimeType:
+		rc, err := NewResponseTimeChecker(tmp.Name, tmp.Path, tmp.Value)
+		if err != nil {
+			return err
+		}
+		cch.ContentChecker = rc
+	case TLSExpiryType:
+		tc, err := NewTLSExpiryChecker(tmp.Name, tmp.Value)
+		if err != nil {
+			return err
+		}
+		cch.ContentChecker = tc
+	case TLSHostnameType:
+		cch.ContentChecker = NewTLSHostnameChecker(tmp.Name)
+	case TLSChainType:
+		cch.ContentChecker = NewTLSChainChecker(tmp.Name)
+	case TLSVersionType:
+		tc, err := NewTLSVersionChecker(tmp.Name, tmp.Value)
+		if err != nil {
+			return err
+		}
+		cch.ContentChecker = tc
+	case DNSRecordsType:
+		dc, err := NewDNSRecordsChecker(tmp.Name, tmp.Match, tmp.Value)
+		if err != nil {
+			return err
+		}
+		cch.ContentChecker = dc
+	case ReachabilityType:
+		rc, err := NewReachabilityChecker(tmp.Name, tmp.Path, tmp.Value)
+		if err != nil {
+			return err
+		}
+		cch.ContentChecker = rc
 	default:
 		return fmt.Errorf("unsupported contentCheck config: '%s'", tmp.CheckType)
 
@@ -56,3 +119,55 @@ func (cch *ContentCheckerHolder) UnmarshalYAML(unmarshal func(interface{}) error
 
 	return nil
 }
+
+// Equal reports whether both holders have the same checker config.
+func (cch ContentCheckerHolder) Equal(y ContentCheckerHolder) bool {
+	switch x := cch.ContentChecker.(type) {
+	case *RegexChecker:
+		yc, ok := y.ContentChecker.(*RegexChecker)
+		return ok && x.Equal(yc)
+	case *HtmlXPathChecker:
+		yc, ok := y.ContentChecker.(*HtmlXPathChecker)
+		return ok && x.Equal(yc)
+	case *JsonPathChecker:
+		yc, ok := y.ContentChecker.(*JsonPathChecker)
+		return ok && x.Equal(yc)
+	case *HtmlRenderSelectorChecker:
+		yc, ok := y.ContentChecker.(*HtmlRenderSelectorChecker)
+		return ok && x.Equal(yc)
+	case *RedirectChecker:
+		yc, ok := y.ContentChecker.(*RedirectChecker)
+		return ok && x.Equal(yc)
+	case *HeaderChecker:
+		yc, ok := y.ContentChecker.(*HeaderChecker)
+		return ok && x.Equal(yc)
+	case *CookieChecker:
+		yc, ok := y.ContentChecker.(*CookieChecker)
+		return ok && x.Equal(yc)
+	case *ResponseTimeChecker:
+		yc, ok := y.ContentChecker.(*ResponseTimeChecker)
+		return ok && x.Equal(yc)
+	case *TLSExpiryChecker:
+		yc, ok := y.ContentChecker.(*TLSExpiryChecker)
+		return ok && x.Equal(yc)
+	case *TLSHostnameChecker:
+		yc, ok := y.ContentChecker.(*TLSHostnameChecker)
+		return ok && x.Equal(yc)
+	case *TLSChainChecker:
+		yc, ok := y.ContentChecker.(*TLSChainChecker)
+		return ok && x.Equal(yc)
+	case *TLSVersionChecker:
+		yc, ok := y.ContentChecker.(*TLSVersionChecker)
+		return ok && x.Equal(yc)
+	case *DNSRecordsChecker:
+		yc, ok := y.ContentChecker.(*DNSRecordsChecker)
+		return ok && x.Equal(yc)
+	case *ReachabilityChecker:
+		yc, ok := y.ContentChecker.(*ReachabilityChecker)
+		return ok && x.Equal(yc)
+	case nil:
+		return y.ContentChecker == nil
+	}
+
+	return false
+}
@@ -0,0 +1,159 @@
+package content_checkers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"io"
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// ContainsMatch is for records which have to include all expected values.
+const ContainsMatch MatchMode = "contains"
+
+// DNSRecordsChecker checks the records a dns monitor resolved.
+type DNSRecordsChecker struct {
+	name     string
+	mode     MatchMode
+	expected []string
+}
+
+// NewDNSRecordsChecker takes a comma separated list of values. The mode
+// defaults to equals, the exact set of records, if there is one and to
+// exists otherwise. contains needs all values among the records, regex
+// needs all records to match value.
+func NewDNSRecordsChecker(name string, mode MatchMode, value string) (*DNSRecordsChecker, error) {
+	var expected []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			expected = append(expected, v)
+		}
+	}
+
+	switch mode {
+	case "":
+		mode = ExistsMatch
+		if len(expected) > 0 {
+			mode = EqualsMatch
+		}
+	case ExistsMatch, NotPresentMatch:
+	case EqualsMatch, ContainsMatch:
+		if len(expected) == 0 {
+			return nil, fmt.Errorf("missing value of '%s'", name)
+		}
+	case RegexMatch:
+		// Commas are part of the regex.
+		expected = []string{value}
+		if _, err := regexp.Compile(value); err != nil {
+			return nil, fmt.Errorf("invalid regex '%s' in '%s': %v", value, name, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported match '%s' in '%s'", mode, name)
+	}
+
+	return &DNSRecordsChecker{
+		name:     name,
+		mode:     mode,
+		expected: expected,
+	}, nil
+}
+
+// sameRecord compares IP addresses by value and names without the trailing
+// dot, ignoring case.
+func sameRecord(x, y string) bool {
+	if ipx, ipy := net.ParseIP(x), net.ParseIP(y); ipx != nil && ipy != nil {
+		return ipx.Equal(ipy)
+	}
+
+	return strings.EqualFold(strings.TrimSuffix(x, "."), strings.TrimSuffix(y, "."))
+}
+
+func containsRecord(records []string, v string) bool {
+	for _, r := range records {
+		if sameRecord(r, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *DNSRecordsChecker) String() string {
+	switch d.mode {
+	case ExistsMatch:
+		return fmt.Sprintf("%s - records exist", d.name)
+	case NotPresentMatch:
+		return fmt.Sprintf("%s - no records", d.name)
+	case ContainsMatch:
+		return fmt.Sprintf("%s - records contain '%s'", d.name, strings.Join(d.expected, ", "))
+	case RegexMatch:
+		return fmt.Sprintf("%s - records match '%s'", d.name, d.expected[0])
+	default:
+		return fmt.Sprintf("%s - records are '%s'", d.name, strings.Join(d.expected, ", "))
+	}
+}
+
+func (d *DNSRecordsChecker) CheckResponse(resp *Response) (bool, error) {
+	records := resp.Records
+	switch d.mode {
+	case ExistsMatch:
+		return len(records) > 0, nil
+	case NotPresentMatch:
+		return len(records) == 0, nil
+	case EqualsMatch:
+		for _, r := range records {
+			if !containsRecord(d.expected, r) {
+				return false, nil
+			}
+		}
+		fallthrough
+	case ContainsMatch:
+		for _, v := range d.expected {
+			if !containsRecord(records, v) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case RegexMatch:
+		rx, err := regexp.Compile(d.expected[0])
+		if err != nil {
+			return false, err
+		}
+		for _, r := range records {
+			if !rx.MatchString(r) {
+				return false, nil
+			}
+		}
+		return len(records) > 0, nil
+	}
+
+	return false, nil
+}
+
+func (d *DNSRecordsChecker) Check(r io.Reader) (bool, error) {
+	return false, errors.New("dns record checks need the records of a dns monitor")
+}
+
+func (d *DNSRecordsChecker) CheckRender(p *rod.Page) (bool, error) {
+	return false, errors.New("dns records aren't available for rendered pages")
+}
+
+func (d *DNSRecordsChecker) Type() string {
+	return "DNSRecordsChecker"
+}
+
+func (d *DNSRecordsChecker) Equal(y *DNSRecordsChecker) bool {
+	return d.name == y.name && d.mode == y.mode && reflect.DeepEqual(d.expected, y.expected)
+}
+
+// DefaultDNSChecks are used by dns monitors without checks.
+func DefaultDNSChecks() []ContentCheckerHolder {
+	exists, _ := NewDNSRecordsChecker("resolves", ExistsMatch, "")
+
+	return []ContentCheckerHolder{
+		{ContentChecker: exists},
+	}
+}
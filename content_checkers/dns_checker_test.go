@@ -0,0 +1,71 @@
+package content_checkers_test
+
+import (
+	"testing"
+	"website-monitor/content_checkers"
+)
+
+func TestDNSRecordsChecker_CheckResponse(t *testing.T) {
+	resp := &content_checkers.Response{
+		Records: []string{"10 mail.example.com.", "20 backup.example.com."},
+	}
+
+	tests := []struct {
+		name   string
+		mode   content_checkers.MatchMode
+		value  string
+		result bool
+	}{
+		{name: "exists by default", result: true},
+		{name: "not present", mode: content_checkers.NotPresentMatch, result: false},
+		{name: "equals by default", value: "20 backup.example.com, 10 MAIL.example.com.", result: true},
+		{name: "equals missing record", value: "10 mail.example.com", result: false},
+		{name: "equals extra record", mode: content_checkers.EqualsMatch, value: "10 mail.example.com, 20 backup.example.com, 30 other.example.com", result: false},
+		{name: "contains", mode: content_checkers.ContainsMatch, value: "10 mail.example.com", result: true},
+		{name: "contains missing", mode: content_checkers.ContainsMatch, value: "30 other.example.com", result: false},
+		{name: "regex all records", mode: content_checkers.RegexMatch, value: `\.example\.com\.$`, result: true},
+		{name: "regex not all records", mode: content_checkers.RegexMatch, value: "^10 ", result: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := content_checkers.NewDNSRecordsChecker(test.name, test.mode, test.value)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			res, err := c.CheckResponse(resp)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+			if res != test.result {
+				t.Errorf("got %t, expected %t", res, test.result)
+			}
+		})
+	}
+}
+
+func TestDNSRecordsChecker_CheckResponseIPs(t *testing.T) {
+	resp := &content_checkers.Response{Records: []string{"2001:db8::1"}}
+
+	c, _ := content_checkers.NewDNSRecordsChecker("ip", content_checkers.EqualsMatch, "2001:0db8:0:0:0:0:0:1")
+	if res, _ := c.CheckResponse(resp); !res {
+		t.Error("got false, expected addresses to be compared by value")
+	}
+
+	c, _ = content_checkers.NewDNSRecordsChecker("none", content_checkers.RegexMatch, ".")
+	if res, _ := c.CheckResponse(&content_checkers.Response{}); res {
+		t.Error("got true, expected regex to need records")
+	}
+}
+
+func TestNewDNSRecordsChecker_Invalid(t *testing.T) {
+	if _, err := content_checkers.NewDNSRecordsChecker("equals", content_checkers.EqualsMatch, ""); err == nil {
+		t.Error("got nil, expected an error without values")
+	}
+	if _, err := content_checkers.NewDNSRecordsChecker("mode", "starts_with", "x"); err == nil {
+		t.Error("got nil, expected an error for an unsupported match")
+	}
+	if _, err := content_checkers.NewDNSRecordsChecker("regex", content_checkers.RegexMatch, "("); err == nil {
+		t.Error("got nil, expected an error for an invalid regex")
+	}
+}
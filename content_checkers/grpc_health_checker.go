@@ -0,0 +1,46 @@
+package content_checkers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"io"
+)
+
+// GrpcServing is the health status of a service which is up.
+const GrpcServing = "SERVING"
+
+// GrpcHealthChecker reports whether a grpc health check got SERVING, so the
+// status counts as a check like the status code of a http response.
+type GrpcHealthChecker struct {
+	service string
+	got     string
+}
+
+func NewGrpcHealthChecker(service, got string) *GrpcHealthChecker {
+	return &GrpcHealthChecker{
+		service: service,
+		got:     got,
+	}
+}
+
+func (g *GrpcHealthChecker) String() string {
+	if g.service == "" {
+		return fmt.Sprintf("grpc health %s - expected %s", g.got, GrpcServing)
+	}
+
+	return fmt.Sprintf("grpc health of %s %s - expected %s", g.service, g.got, GrpcServing)
+}
+
+// Check ignores the body, the status is known up front.
+func (g *GrpcHealthChecker) Check(r io.Reader) (bool, error) {
+	return g.got == GrpcServing, nil
+}
+
+func (g *GrpcHealthChecker) CheckRender(p *rod.Page) (bool, error) {
+	return false, errors.New("grpc health isn't available for rendered pages")
+}
+
+func (g *GrpcHealthChecker) Type() string {
+	return "GrpcHealthChecker"
+}
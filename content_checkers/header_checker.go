@@ -0,0 +1,191 @@
+package content_checkers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"io"
+	"net/http"
+	"regexp"
+)
+
+type MatchMode string
+
+const (
+	ExistsMatch     MatchMode = "exists"
+	EqualsMatch     MatchMode = "equals"
+	RegexMatch      MatchMode = "regex"
+	NotPresentMatch MatchMode = "not_present"
+)
+
+// matcher checks the values of a header or cookie according to a mode.
+type matcher struct {
+	mode  MatchMode
+	value string
+}
+
+// newMatcher defaults the mode to equals if there is a value to compare
+// with, and to exists otherwise.
+func newMatcher(mode MatchMode, value string) (matcher, error) {
+	switch mode {
+	case "":
+		mode = ExistsMatch
+		if value != "" {
+			mode = EqualsMatch
+		}
+	case ExistsMatch, EqualsMatch, NotPresentMatch:
+	case RegexMatch:
+		if _, err := regexp.Compile(value); err != nil {
+			return matcher{}, fmt.Errorf("invalid regex '%s': %v", value, err)
+		}
+	default:
+		return matcher{}, fmt.Errorf("unsupported match '%s'", mode)
+	}
+
+	return matcher{mode: mode, value: value}, nil
+}
+
+// match reports whether any of values matches, or for not_present that
+// there are none.
+func (m matcher) match(values []string) (bool, error) {
+	switch m.mode {
+	case ExistsMatch:
+		return len(values) > 0, nil
+	case NotPresentMatch:
+		return len(values) == 0, nil
+	case EqualsMatch:
+		for _, v := range values {
+			if v == m.value {
+				return true, nil
+			}
+		}
+	case RegexMatch:
+		rx, err := regexp.Compile(m.value)
+		if err != nil {
+			return false, err
+		}
+		for _, v := range values {
+			if rx.MatchString(v) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (m matcher) String() string {
+	switch m.mode {
+	case ExistsMatch:
+		return "exists"
+	case NotPresentMatch:
+		return "is not present"
+	case RegexMatch:
+		return fmt.Sprintf("matches '%s'", m.value)
+	default:
+		return fmt.Sprintf("is '%s'", m.value)
+	}
+}
+
+// HeaderChecker checks a header of the response.
+type HeaderChecker struct {
+	name    string
+	header  string
+	matcher matcher
+}
+
+func NewHeaderChecker(name, header string, mode MatchMode, value string) (*HeaderChecker, error) {
+	if header == "" {
+		return nil, fmt.Errorf("missing header name in path of '%s'", name)
+	}
+
+	m, err := newMatcher(mode, value)
+	if err != nil {
+		return nil, fmt.Errorf("%v in '%s'", err, name)
+	}
+
+	return &HeaderChecker{
+		name:    name,
+		header:  http.CanonicalHeaderKey(header),
+		matcher: m,
+	}, nil
+}
+
+func (h *HeaderChecker) String() string {
+	return fmt.Sprintf("%s - header '%s' %s", h.name, h.header, h.matcher)
+}
+
+func (h *HeaderChecker) CheckResponse(resp *Response) (bool, error) {
+	return h.matcher.match(resp.Header.Values(h.header))
+}
+
+func (h *HeaderChecker) Check(r io.Reader) (bool, error) {
+	return false, errors.New("header checks need the response, not just the body")
+}
+
+func (h *HeaderChecker) CheckRender(p *rod.Page) (bool, error) {
+	return false, errors.New("headers aren't available for rendered pages")
+}
+
+func (h *HeaderChecker) Type() string {
+	return "HeaderChecker"
+}
+
+func (h *HeaderChecker) Equal(y *HeaderChecker) bool {
+	return h.name == y.name && h.header == y.header && h.matcher == y.matcher
+}
+
+// CookieChecker checks the value of a cookie set by the response.
+type CookieChecker struct {
+	name    string
+	cookie  string
+	matcher matcher
+}
+
+func NewCookieChecker(name, cookie string, mode MatchMode, value string) (*CookieChecker, error) {
+	if cookie == "" {
+		return nil, fmt.Errorf("missing cookie name in path of '%s'", name)
+	}
+
+	m, err := newMatcher(mode, value)
+	if err != nil {
+		return nil, fmt.Errorf("%v in '%s'", err, name)
+	}
+
+	return &CookieChecker{
+		name:    name,
+		cookie:  cookie,
+		matcher: m,
+	}, nil
+}
+
+func (c *CookieChecker) String() string {
+	return fmt.Sprintf("%s - cookie '%s' %s", c.name, c.cookie, c.matcher)
+}
+
+func (c *CookieChecker) CheckResponse(resp *Response) (bool, error) {
+	var values []string
+	for _, cookie := range resp.Cookies {
+		if cookie.Name == c.cookie {
+			values = append(values, cookie.Value)
+		}
+	}
+
+	return c.matcher.match(values)
+}
+
+func (c *CookieChecker) Check(r io.Reader) (bool, error) {
+	return false, errors.New("cookie checks need the response, not just the body")
+}
+
+func (c *CookieChecker) CheckRender(p *rod.Page) (bool, error) {
+	return false, errors.New("cookies aren't available for rendered pages")
+}
+
+func (c *CookieChecker) Type() string {
+	return "CookieChecker"
+}
+
+func (c *CookieChecker) Equal(y *CookieChecker) bool {
+	return c.name == y.name && c.cookie == y.cookie && c.matcher == y.matcher
+}
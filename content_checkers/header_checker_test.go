@@ -0,0 +1,101 @@
+package content_checkers_test
+
+import (
+	"net/http"
+	"testing"
+	"website-monitor/content_checkers"
+)
+
+func TestHeaderChecker_CheckResponse(t *testing.T) {
+	resp := &content_checkers.Response{
+		Header: http.Header{
+			"Cache-Control": []string{"public, max-age=60"},
+			"X-Stock-Level": []string{"3"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		mode   content_checkers.MatchMode
+		value  string
+		result bool
+	}{
+		{name: "exists", header: "x-stock-level", mode: content_checkers.ExistsMatch, result: true},
+		{name: "exists by default", header: "X-Stock-Level", result: true},
+		{name: "missing", header: "ETag", mode: content_checkers.ExistsMatch, result: false},
+		{name: "equals", header: "X-Stock-Level", mode: content_checkers.EqualsMatch, value: "3", result: true},
+		{name: "equals by default", header: "X-Stock-Level", value: "0", result: false},
+		{name: "regex", header: "Cache-Control", mode: content_checkers.RegexMatch, value: `max-age=\d+`, result: true},
+		{name: "regex no match", header: "Cache-Control", mode: content_checkers.RegexMatch, value: "no-store", result: false},
+		{name: "not present", header: "Set-Cookie", mode: content_checkers.NotPresentMatch, result: true},
+		{name: "present", header: "Cache-Control", mode: content_checkers.NotPresentMatch, result: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := content_checkers.NewHeaderChecker(test.name, test.header, test.mode, test.value)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			res, err := c.CheckResponse(resp)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+			if res != test.result {
+				t.Errorf("got %t, expected %t", res, test.result)
+			}
+		})
+	}
+}
+
+func TestCookieChecker_CheckResponse(t *testing.T) {
+	resp := &content_checkers.Response{
+		Cookies: []*http.Cookie{
+			{Name: "session", Value: "abc123"},
+			{Name: "region", Value: "eu"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		cookie string
+		mode   content_checkers.MatchMode
+		value  string
+		result bool
+	}{
+		{name: "exists", cookie: "session", result: true},
+		{name: "equals", cookie: "region", value: "eu", result: true},
+		{name: "regex", cookie: "session", mode: content_checkers.RegexMatch, value: "^[a-z0-9]+$", result: true},
+		{name: "not present", cookie: "tracking", mode: content_checkers.NotPresentMatch, result: true},
+		{name: "present", cookie: "region", mode: content_checkers.NotPresentMatch, result: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := content_checkers.NewCookieChecker(test.name, test.cookie, test.mode, test.value)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			res, err := c.CheckResponse(resp)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+			if res != test.result {
+				t.Errorf("got %t, expected %t", res, test.result)
+			}
+		})
+	}
+}
+
+func TestNewHeaderChecker_Invalid(t *testing.T) {
+	if _, err := content_checkers.NewHeaderChecker("no header", "", content_checkers.ExistsMatch, ""); err == nil {
+		t.Error("got nil, expected an error without a header name")
+	}
+	if _, err := content_checkers.NewHeaderChecker("mode", "ETag", "contains", "x"); err == nil {
+		t.Error("got nil, expected an error for an unsupported match")
+	}
+	if _, err := content_checkers.NewHeaderChecker("regex", "ETag", content_checkers.RegexMatch, "("); err == nil {
+		t.Error("got nil, expected an error for an invalid regex")
+	}
+}
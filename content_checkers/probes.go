@@ -0,0 +1,66 @@
+package content_checkers
+
+import "time"
+
+// Probes are the connects of a reachability monitor.
+type Probes struct {
+	Sent     int
+	Received int
+	// Min, Avg and Max are the connect times of the received probes, 0
+	// without any.
+	Min time.Duration
+	Avg time.Duration
+	Max time.Duration
+}
+
+// Loss is the ratio of probes which failed, 1 without probes.
+func (p Probes) Loss() float64 {
+	if p.Sent == 0 {
+		return 1
+	}
+
+	return float64(p.Sent-p.Received) / float64(p.Sent)
+}
+
+// Add records a probe, with its connect time if it was received.
+func (p *Probes) Add(received bool, d time.Duration) {
+	p.Sent++
+	if !received {
+		return
+	}
+
+	p.Received++
+	if p.Received == 1 || d < p.Min {
+		p.Min = d
+	}
+	if d > p.Max {
+		p.Max = d
+	}
+	p.Avg += (d - p.Avg) / time.Duration(p.Received)
+}
+
+type ProbeStat string
+
+const (
+	LossStat ProbeStat = "loss"
+	MinStat  ProbeStat = "min"
+	AvgStat  ProbeStat = "avg"
+	MaxStat  ProbeStat = "max"
+)
+
+// LatencyStats are the stats which are connect times.
+var LatencyStats = []ProbeStat{MinStat, AvgStat, MaxStat}
+
+// Latency returns the connect time of stat, or false if it isn't one.
+func (p Probes) Latency(stat ProbeStat) (time.Duration, bool) {
+	switch stat {
+	case MinStat:
+		return p.Min, true
+	case AvgStat:
+		return p.Avg, true
+	case MaxStat:
+		return p.Max, true
+	}
+
+	return 0, false
+}
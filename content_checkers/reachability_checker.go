@@ -0,0 +1,103 @@
+package content_checkers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxLoss is the loss above which reachability monitors without
+// checks fail.
+const DefaultMaxLoss = 0.5
+
+// ReachabilityChecker fails when the loss or a latency of the probes of a
+// reachability monitor is above a threshold.
+type ReachabilityChecker struct {
+	name    string
+	stat    ProbeStat
+	maxLoss float64
+	max     time.Duration
+}
+
+// NewReachabilityChecker checks the stat named by path, loss if empty,
+// against value. The loss is a percentage like "20%", the latencies min,
+// avg and max are durations.
+func NewReachabilityChecker(name, path, value string) (*ReachabilityChecker, error) {
+	rc := &ReachabilityChecker{
+		name: name,
+		stat: ProbeStat(path),
+	}
+	if rc.stat == "" {
+		rc.stat = LossStat
+	}
+
+	if rc.stat == LossStat {
+		loss, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil || loss < 0 || loss > 100 {
+			return nil, fmt.Errorf("invalid loss '%s' in '%s', expected a percentage like 20%%", value, name)
+		}
+		rc.maxLoss = loss / 100
+		return rc, nil
+	}
+
+	if _, ok := (Probes{}).Latency(rc.stat); !ok {
+		return nil, fmt.Errorf("invalid reachability stat '%s' in '%s', expected loss, min, avg or max", path, name)
+	}
+	max, err := time.ParseDuration(value)
+	if err != nil || max <= 0 {
+		return nil, fmt.Errorf("invalid latency threshold '%s' in '%s'", value, name)
+	}
+	rc.max = max
+
+	return rc, nil
+}
+
+func (r *ReachabilityChecker) String() string {
+	if r.stat == LossStat {
+		return fmt.Sprintf("%s - loss at most %s%%", r.name, strconv.FormatFloat(r.maxLoss*100, 'f', -1, 64))
+	}
+
+	return fmt.Sprintf("%s - %s latency below %s", r.name, r.stat, r.max)
+}
+
+// CheckResponse fails latency checks when no probe was received.
+func (r *ReachabilityChecker) CheckResponse(resp *Response) (bool, error) {
+	if resp.Probes == nil {
+		return false, errors.New("reachability checks need the probes of a reachability monitor")
+	}
+	if r.stat == LossStat {
+		return resp.Probes.Loss() <= r.maxLoss, nil
+	}
+
+	d, _ := resp.Probes.Latency(r.stat)
+
+	return resp.Probes.Received > 0 && d <= r.max, nil
+}
+
+func (r *ReachabilityChecker) Check(rd io.Reader) (bool, error) {
+	return false, errors.New("reachability checks need the probes of a reachability monitor")
+}
+
+func (r *ReachabilityChecker) CheckRender(p *rod.Page) (bool, error) {
+	return false, errors.New("probes aren't available for rendered pages")
+}
+
+func (r *ReachabilityChecker) Type() string {
+	return "ReachabilityChecker"
+}
+
+func (r *ReachabilityChecker) Equal(y *ReachabilityChecker) bool {
+	return r.name == y.name && r.stat == y.stat && r.maxLoss == y.maxLoss && r.max == y.max
+}
+
+// DefaultReachabilityChecks are used by reachability monitors without
+// checks.
+func DefaultReachabilityChecks() []ContentCheckerHolder {
+	return []ContentCheckerHolder{
+		{ContentChecker: &ReachabilityChecker{name: "reachable", stat: LossStat, maxLoss: DefaultMaxLoss}},
+	}
+}
@@ -0,0 +1,77 @@
+package content_checkers_test
+
+import (
+	"testing"
+	"time"
+	"website-monitor/content_checkers"
+)
+
+func TestProbes_Add(t *testing.T) {
+	p := content_checkers.Probes{}
+	if p.Loss() != 1 {
+		t.Errorf("got loss %f without probes, expected 1", p.Loss())
+	}
+
+	p.Add(true, 30*time.Millisecond)
+	p.Add(false, 0)
+	p.Add(true, 10*time.Millisecond)
+	p.Add(true, 20*time.Millisecond)
+
+	if p.Sent != 4 || p.Received != 3 {
+		t.Errorf("got %d of %d received, expected 3 of 4", p.Received, p.Sent)
+	}
+	if p.Loss() != 0.25 {
+		t.Errorf("got loss %f, expected 0.25", p.Loss())
+	}
+	if p.Min != 10*time.Millisecond || p.Avg != 20*time.Millisecond || p.Max != 30*time.Millisecond {
+		t.Errorf("got min %s, avg %s and max %s, expected 10ms, 20ms and 30ms", p.Min, p.Avg, p.Max)
+	}
+}
+
+func TestReachabilityChecker_CheckResponse(t *testing.T) {
+	resp := &content_checkers.Response{
+		Probes: &content_checkers.Probes{Sent: 5, Received: 4, Min: 5 * time.Millisecond, Avg: 10 * time.Millisecond, Max: 40 * time.Millisecond},
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		value  string
+		result bool
+	}{
+		{name: "loss by default", value: "20%", result: true},
+		{name: "loss above", path: "loss", value: "10%", result: false},
+		{name: "loss without percent sign", path: "loss", value: "50", result: true},
+		{name: "avg", path: "avg", value: "10ms", result: true},
+		{name: "max above", path: "max", value: "30ms", result: false},
+		{name: "min", path: "min", value: "10ms", result: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := content_checkers.NewReachabilityChecker(test.name, test.path, test.value)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			res, err := c.CheckResponse(resp)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+			if res != test.result {
+				t.Errorf("got %t, expected %t", res, test.result)
+			}
+		})
+	}
+}
+
+func TestNewReachabilityChecker_Invalid(t *testing.T) {
+	if _, err := content_checkers.NewReachabilityChecker("loss", "loss", "120%"); err == nil {
+		t.Error("got nil, expected an error for a loss above 100%")
+	}
+	if _, err := content_checkers.NewReachabilityChecker("stat", "median", "10ms"); err == nil {
+		t.Error("got nil, expected an error for an unknown stat")
+	}
+	if _, err := content_checkers.NewReachabilityChecker("latency", "avg", "fast"); err == nil {
+		t.Error("got nil, expected an error for an invalid duration")
+	}
+}
@@ -0,0 +1,102 @@
+package content_checkers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// RedirectChecker matches a regex against the final URL of a response, or
+// against the Location of one of its redirects.
+type RedirectChecker struct {
+	name          string
+	hop           int
+	regex         string
+	expectedMatch bool
+}
+
+// NewRedirectChecker checks the final URL if path is empty or "final", or
+// the Location of the redirect numbered by path, starting at 1.
+func NewRedirectChecker(name, path, regex string, expectedMatch bool) (*RedirectChecker, error) {
+	hop := 0
+	if path != "" && path != "final" {
+		var err error
+		hop, err = strconv.Atoi(path)
+		if err != nil || hop < 1 {
+			return nil, fmt.Errorf("invalid redirect path '%s' in '%s', expected 'final' or a hop number from 1", path, name)
+		}
+	}
+
+	if _, err := regexp.Compile(regex); err != nil {
+		return nil, fmt.Errorf("invalid regex in '%s': %v", name, err)
+	}
+
+	return &RedirectChecker{
+		name:          name,
+		hop:           hop,
+		regex:         regex,
+		expectedMatch: expectedMatch,
+	}, nil
+}
+
+func (c *RedirectChecker) target() string {
+	if c.hop == 0 {
+		return "final url"
+	}
+
+	return fmt.Sprintf("redirect %d location", c.hop)
+}
+
+func (c *RedirectChecker) String() string {
+	if c.expectedMatch {
+		return fmt.Sprintf("%s - %s matches '%s'", c.name, c.target(), c.regex)
+	} else {
+		return fmt.Sprintf("%s - %s doesn't match '%s'", c.name, c.target(), c.regex)
+	}
+}
+
+func (c *RedirectChecker) CheckResponse(resp *Response) (bool, error) {
+	value := resp.FinalURL
+	if c.hop > 0 {
+		if c.hop > len(resp.Redirects) {
+			// A redirect which didn't happen can't match.
+			return !c.expectedMatch, nil
+		}
+		value = resp.Redirects[c.hop-1].Location
+	}
+
+	rx, err := regexp.Compile(c.regex)
+	if err != nil {
+		return false, err
+	}
+
+	return rx.MatchString(value) == c.expectedMatch, nil
+}
+
+func (c *RedirectChecker) Check(r io.Reader) (bool, error) {
+	return false, errors.New("redirect checks need the response, not just the body")
+}
+
+func (c *RedirectChecker) CheckRender(p *rod.Page) (bool, error) {
+	if c.hop > 0 {
+		return false, errors.New("redirect locations aren't available for rendered pages")
+	}
+
+	info, err := p.Info()
+	if err != nil {
+		return false, err
+	}
+
+	return c.CheckResponse(&Response{FinalURL: info.URL})
+}
+
+func (c *RedirectChecker) Type() string {
+	return "RedirectChecker"
+}
+
+func (c *RedirectChecker) Equal(y *RedirectChecker) bool {
+	return c.name == y.name && c.hop == y.hop && c.regex == y.regex && c.expectedMatch == y.expectedMatch
+}
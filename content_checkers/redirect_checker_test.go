@@ -0,0 +1,56 @@
+package content_checkers_test
+
+import (
+	"testing"
+	"website-monitor/content_checkers"
+)
+
+func TestRedirectChecker_CheckResponse(t *testing.T) {
+	resp := &content_checkers.Response{
+		StatusCode: 200,
+		FinalURL:   "https://shop.example.com/sold-out",
+		Redirects: []content_checkers.Redirect{
+			{URL: "http://shop.example.com/product", StatusCode: 301, Location: "https://shop.example.com/product"},
+			{URL: "https://shop.example.com/product", StatusCode: 302, Location: "/sold-out"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		regex         string
+		expectedMatch bool
+		result        bool
+	}{
+		{name: "final url matches", path: "", regex: "/sold-out$", expectedMatch: true, result: true},
+		{name: "final url doesn't match", path: "final", regex: "/sold-out$", expectedMatch: false, result: false},
+		{name: "hop location matches", path: "1", regex: "^https://", expectedMatch: true, result: true},
+		{name: "second hop location", path: "2", regex: "sold-out", expectedMatch: false, result: false},
+		{name: "missing hop never matches", path: "3", regex: ".*", expectedMatch: false, result: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := content_checkers.NewRedirectChecker(test.name, test.path, test.regex, test.expectedMatch)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			res, err := c.CheckResponse(resp)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+			if res != test.result {
+				t.Errorf("got %t, expected %t", res, test.result)
+			}
+		})
+	}
+}
+
+func TestNewRedirectChecker_Invalid(t *testing.T) {
+	if _, err := content_checkers.NewRedirectChecker("hop", "0", ".*", true); err == nil {
+		t.Error("got nil, expected an error for hop 0")
+	}
+	if _, err := content_checkers.NewRedirectChecker("regex", "final", "(", true); err == nil {
+		t.Error("got nil, expected an error for an invalid regex")
+	}
+}
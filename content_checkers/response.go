@@ -0,0 +1,98 @@
+package content_checkers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"time"
+)
+
+// Response is what checkers which implement ResponseChecker see of an HTTP
+// response, besides its body.
+type Response struct {
+	StatusCode int
+	// FinalURL is the URL of the last request, after following redirects.
+	FinalURL string
+	// Redirects are the redirect responses in the order they were received,
+	// including the last one if it wasn't followed.
+	Redirects []Redirect
+	Header    http.Header
+	// Cookies are the cookies set by the final response.
+	Cookies []*http.Cookie
+	Timings Timings
+	// TLS is the connection state of the final response, nil without TLS.
+	TLS *tls.ConnectionState
+	// ServerName is the name the certificate is expected to be valid for.
+	ServerName string
+	// Roots verify the certificate chain, the system roots if nil.
+	Roots *x509.CertPool
+	// Records are the answers of a dns monitor, formatted like in a zone
+	// file without the name, class and TTL, e.g. "10 mail.example.com.".
+	Records []string
+	// Probes are those of a reachability monitor.
+	Probes *Probes
+}
+
+type Redirect struct {
+	URL        string
+	StatusCode int
+	Location   string
+}
+
+// ResponseChecker is implemented by checkers which check the response
+// instead of, or as well as, the body. Monitors use CheckResponse instead of
+// Check for them.
+type ResponseChecker interface {
+	CheckResponse(resp *Response) (bool, error)
+}
+
+// RenderTimeChecker is implemented by checkers which check how long a page
+// took to render, the only part of the response known for rendered pages.
+// Render monitors use CheckRenderTime instead of CheckRender for them.
+type RenderTimeChecker interface {
+	CheckRenderTime(total time.Duration) (bool, error)
+}
+
+// Timings are the durations of the phases of a request, summed over all
+// requests when redirects were followed. Phases which didn't happen, like
+// DNS for an IP address or TLS for plain HTTP, are 0.
+type Timings struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// TTFB is the time from sending the request until the first byte of the
+	// response.
+	TTFB time.Duration
+	// Total is the time from starting the request until the body was read.
+	Total time.Duration
+}
+
+type Phase string
+
+const (
+	DNSPhase     Phase = "dns"
+	ConnectPhase Phase = "connect"
+	TLSPhase     Phase = "tls"
+	TTFBPhase    Phase = "ttfb"
+	TotalPhase   Phase = "total"
+)
+
+var Phases = []Phase{DNSPhase, ConnectPhase, TLSPhase, TTFBPhase, TotalPhase}
+
+// Get returns the duration of phase, or false if it isn't a phase.
+func (t Timings) Get(phase Phase) (time.Duration, bool) {
+	switch phase {
+	case DNSPhase:
+		return t.DNS, true
+	case ConnectPhase:
+		return t.Connect, true
+	case TLSPhase:
+		return t.TLS, true
+	case TTFBPhase:
+		return t.TTFB, true
+	case TotalPhase:
+		return t.Total, true
+	}
+
+	return 0, false
+}
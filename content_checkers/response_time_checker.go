@@ -0,0 +1,81 @@
+package content_checkers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"io"
+	"time"
+)
+
+// ResponseTimeChecker fails when a phase of the request took longer than a
+// threshold.
+type ResponseTimeChecker struct {
+	name      string
+	phase     Phase
+	threshold time.Duration
+}
+
+// NewResponseTimeChecker checks the phase named by path, total if empty,
+// against the threshold duration in value.
+func NewResponseTimeChecker(name, path, value string) (*ResponseTimeChecker, error) {
+	phase := Phase(path)
+	if phase == "" {
+		phase = TotalPhase
+	}
+	if _, ok := (Timings{}).Get(phase); !ok {
+		return nil, fmt.Errorf("invalid response time phase '%s' in '%s', expected one of %v", path, name, Phases)
+	}
+
+	threshold, err := time.ParseDuration(value)
+	if err != nil || threshold <= 0 {
+		return nil, fmt.Errorf("invalid response time threshold '%s' in '%s'", value, name)
+	}
+
+	return &ResponseTimeChecker{
+		name:      name,
+		phase:     phase,
+		threshold: threshold,
+	}, nil
+}
+
+func (r *ResponseTimeChecker) String() string {
+	return fmt.Sprintf("%s - %s time below %s", r.name, r.phase, r.threshold)
+}
+
+func (r *ResponseTimeChecker) CheckResponse(resp *Response) (bool, error) {
+	d, _ := resp.Timings.Get(r.phase)
+
+	return d <= r.threshold, nil
+}
+
+// CheckRenderTime checks the total time of a rendered page, the other
+// phases aren't known.
+func (r *ResponseTimeChecker) CheckRenderTime(total time.Duration) (bool, error) {
+	if r.phase != TotalPhase {
+		return false, fmt.Errorf("%s time isn't available for rendered pages, only %s", r.phase, TotalPhase)
+	}
+
+	return total <= r.threshold, nil
+}
+
+// Phase returns the phase which is checked.
+func (r *ResponseTimeChecker) Phase() Phase {
+	return r.phase
+}
+
+func (r *ResponseTimeChecker) Check(rd io.Reader) (bool, error) {
+	return false, errors.New("response time checks need the response, not just the body")
+}
+
+func (r *ResponseTimeChecker) CheckRender(p *rod.Page) (bool, error) {
+	return false, errors.New("response times of rendered pages are checked with CheckRenderTime")
+}
+
+func (r *ResponseTimeChecker) Type() string {
+	return "ResponseTimeChecker"
+}
+
+func (r *ResponseTimeChecker) Equal(y *ResponseTimeChecker) bool {
+	return r.name == y.name && r.phase == y.phase && r.threshold == y.threshold
+}
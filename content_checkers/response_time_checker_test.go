@@ -0,0 +1,85 @@
+package content_checkers_test
+
+import (
+	"testing"
+	"time"
+	"website-monitor/content_checkers"
+)
+
+func TestResponseTimeChecker_CheckResponse(t *testing.T) {
+	resp := &content_checkers.Response{
+		Timings: content_checkers.Timings{
+			DNS:   5 * time.Millisecond,
+			TTFB:  300 * time.Millisecond,
+			Total: 1200 * time.Millisecond,
+		},
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		value  string
+		result bool
+	}{
+		{name: "total below", path: "", value: "2s", result: true},
+		{name: "total above", path: "total", value: "1s", result: false},
+		{name: "ttfb below", path: "ttfb", value: "500ms", result: true},
+		{name: "dns above", path: "dns", value: "1ms", result: false},
+		{name: "tls didn't happen", path: "tls", value: "1ms", result: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := content_checkers.NewResponseTimeChecker(test.name, test.path, test.value)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			res, err := c.CheckResponse(resp)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+			if res != test.result {
+				t.Errorf("got %t, expected %t", res, test.result)
+			}
+		})
+	}
+}
+
+func TestResponseTimeChecker_CheckRenderTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		value  string
+		result bool
+		err    bool
+	}{
+		{name: "total below", path: "", value: "2s", result: true},
+		{name: "total above", path: "total", value: "1s", result: false},
+		{name: "phase of a rendered page", path: "ttfb", value: "500ms", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := content_checkers.NewResponseTimeChecker(test.name, test.path, test.value)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			res, err := c.CheckRenderTime(1200 * time.Millisecond)
+			if (err != nil) != test.err {
+				t.Fatalf("got err %v, expected err %t", err, test.err)
+			}
+			if res != test.result {
+				t.Errorf("got %t, expected %t", res, test.result)
+			}
+		})
+	}
+}
+
+func TestNewResponseTimeChecker_Invalid(t *testing.T) {
+	if _, err := content_checkers.NewResponseTimeChecker("phase", "render", "1s"); err == nil {
+		t.Error("got nil, expected an error for an unknown phase")
+	}
+	if _, err := content_checkers.NewResponseTimeChecker("threshold", "total", "fast"); err == nil {
+		t.Error("got nil, expected an error for an invalid threshold")
+	}
+}
@@ -0,0 +1,183 @@
+package content_checkers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// StatusCodeRange is a range of status codes, Min and Max included.
+type StatusCodeRange struct {
+	Min int
+	Max int
+}
+
+func (r StatusCodeRange) String() string {
+	switch {
+	case r.Min == r.Max:
+		return strconv.Itoa(r.Min)
+	case r.Min%100 == 0 && r.Max == r.Min+99:
+		return fmt.Sprintf("%dxx", r.Min/100)
+	default:
+		return fmt.Sprintf("%d-%d", r.Min, r.Max)
+	}
+}
+
+// StatusCodes is a set of accepted status codes, configured as a single
+// code, a class like "2xx", a range like "200-299" or a list of those.
+type StatusCodes []StatusCodeRange
+
+// DefaultStatusCode is accepted when no status codes are configured.
+const DefaultStatusCode = 200
+
+func NewStatusCodes(codes ...int) StatusCodes {
+	var sc StatusCodes
+	for _, code := range codes {
+		sc = append(sc, StatusCodeRange{Min: code, Max: code})
+	}
+
+	return sc
+}
+
+func (sc StatusCodes) Contains(code int) bool {
+	if len(sc) == 0 {
+		return code == DefaultStatusCode
+	}
+
+	for _, r := range sc {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (sc StatusCodes) String() string {
+	if len(sc) == 0 {
+		return strconv.Itoa(DefaultStatusCode)
+	}
+
+	var parts []string
+	for _, r := range sc {
+		parts = append(parts, r.String())
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func parseStatusCode(str string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(str))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code '%s'", str)
+	}
+
+	return code, nil
+}
+
+func parseStatusCodeRange(str string) (StatusCodeRange, error) {
+	str = strings.ToLower(strings.TrimSpace(str))
+
+	if len(str) == 3 && strings.HasSuffix(str, "xx") {
+		class, err := parseStatusCode(str[:1] + "00")
+		if err != nil {
+			return StatusCodeRange{}, fmt.Errorf("invalid status code class '%s'", str)
+		}
+		return StatusCodeRange{Min: class, Max: class + 99}, nil
+	}
+
+	if i := strings.Index(str, "-"); i >= 0 {
+		min, err := parseStatusCode(str[:i])
+		if err != nil {
+			return StatusCodeRange{}, err
+		}
+		max, err := parseStatusCode(str[i+1:])
+		if err != nil {
+			return StatusCodeRange{}, err
+		}
+		if max < min {
+			return StatusCodeRange{}, fmt.Errorf("invalid status code range '%s'", str)
+		}
+		return StatusCodeRange{Min: min, Max: max}, nil
+	}
+
+	code, err := parseStatusCode(str)
+	if err != nil {
+		return StatusCodeRange{}, err
+	}
+
+	return StatusCodeRange{Min: code, Max: code}, nil
+}
+
+func (sc *StatusCodes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	items, ok := raw.([]interface{})
+	if !ok {
+		items = []interface{}{raw}
+	}
+
+	codes := StatusCodes{}
+	for _, item := range items {
+		switch v := item.(type) {
+		case int:
+			r, err := parseStatusCodeRange(strconv.Itoa(v))
+			if err != nil {
+				return err
+			}
+			codes = append(codes, r)
+		case string:
+			r, err := parseStatusCodeRange(v)
+			if err != nil {
+				return err
+			}
+			codes = append(codes, r)
+		case nil:
+		default:
+			return fmt.Errorf("invalid expected_status_code '%v'", v)
+		}
+	}
+	if len(codes) == 0 {
+		codes = nil
+	}
+	*sc = codes
+
+	return nil
+}
+
+// StatusCodeChecker reports whether the status code of a response is one of
+// the expected ones, so the status code counts as a check like any other.
+type StatusCodeChecker struct {
+	expected StatusCodes
+	got      int
+}
+
+func NewStatusCodeChecker(expected StatusCodes, got int) *StatusCodeChecker {
+	return &StatusCodeChecker{
+		expected: expected,
+		got:      got,
+	}
+}
+
+func (s *StatusCodeChecker) String() string {
+	return fmt.Sprintf("status code %d - expected %s", s.got, s.expected)
+}
+
+// Check ignores the body, the status code is known up front.
+func (s *StatusCodeChecker) Check(r io.Reader) (bool, error) {
+	return s.expected.Contains(s.got), nil
+}
+
+func (s *StatusCodeChecker) CheckRender(p *rod.Page) (bool, error) {
+	return false, errors.New("status codes aren't available for rendered pages")
+}
+
+func (s *StatusCodeChecker) Type() string {
+	return "StatusCodeChecker"
+}
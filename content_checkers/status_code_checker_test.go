@@ -0,0 +1,76 @@
+package content_checkers_test
+
+import (
+	yaml "gopkg.in/yaml.v3"
+	"testing"
+	"website-monitor/content_checkers"
+)
+
+func TestStatusCodes_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		str      string
+		accepted []int
+		rejected []int
+		err      bool
+	}{
+		{name: "single code", data: "200", str: "200", accepted: []int{200}, rejected: []int{201, 304}},
+		{name: "list", data: "[200, 304]", str: "200, 304", accepted: []int{200, 304}, rejected: []int{301}},
+		{name: "class", data: `"2xx"`, str: "2xx", accepted: []int{200, 204, 299}, rejected: []int{199, 300}},
+		{name: "range", data: `"200-204"`, str: "200-204", accepted: []int{200, 204}, rejected: []int{205}},
+		{name: "mixed list", data: `["2xx", 304, "401-403"]`, str: "2xx, 304, 401-403", accepted: []int{201, 304, 402}, rejected: []int{301, 404}},
+		{name: "invalid code", data: "99", err: true},
+		{name: "invalid class", data: `"7xx"`, err: true},
+		{name: "reversed range", data: `"299-200"`, err: true},
+		{name: "invalid string", data: `"ok"`, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sc content_checkers.StatusCodes
+			err := yaml.Unmarshal([]byte(test.data), &sc)
+			if (err != nil) != test.err {
+				t.Fatalf("got err %v, expected error: %t", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+
+			if sc.String() != test.str {
+				t.Errorf("got '%s', expected '%s'", sc.String(), test.str)
+			}
+			for _, code := range test.accepted {
+				if !sc.Contains(code) {
+					t.Errorf("expected %d to be accepted", code)
+				}
+			}
+			for _, code := range test.rejected {
+				if sc.Contains(code) {
+					t.Errorf("expected %d to be rejected", code)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusCodes_ContainsDefault(t *testing.T) {
+	var sc content_checkers.StatusCodes
+	if !sc.Contains(200) || sc.Contains(204) {
+		t.Errorf("expected only %d to be accepted without status codes", content_checkers.DefaultStatusCode)
+	}
+}
+
+func TestStatusCodeChecker_Check(t *testing.T) {
+	c := content_checkers.NewStatusCodeChecker(content_checkers.NewStatusCodes(200, 304), 304)
+	if res, err := c.Check(nil); !res || err != nil {
+		t.Errorf("got %t (err: %v), expected true", res, err)
+	}
+
+	c = content_checkers.NewStatusCodeChecker(content_checkers.NewStatusCodes(200, 304), 500)
+	if res, err := c.Check(nil); res || err != nil {
+		t.Errorf("got %t (err: %v), expected false", res, err)
+	}
+	if c.String() != "status code 500 - expected 200, 304" {
+		t.Errorf("got '%s'", c.String())
+	}
+}
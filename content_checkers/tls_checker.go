@@ -0,0 +1,302 @@
+package content_checkers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultExpiryWarning is how long before expiry a certificate fails
+	// tls_expiry checks without a value.
+	DefaultExpiryWarning = 14 * 24 * time.Hour
+	// DefaultMinTLSVersion is the lowest version passing tls_version checks
+	// without a value.
+	DefaultMinTLSVersion = tls.VersionTLS12
+)
+
+var errNoTLS = errors.New("no TLS connection")
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func tlsVersionName(v uint16) string {
+	for name, version := range tlsVersions {
+		if version == v {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("0x%04x", v)
+}
+
+// CertificateExpiry returns the earliest expiry of the certificates the
+// server sent, or the zero time without TLS.
+func CertificateExpiry(state *tls.ConnectionState) time.Time {
+	var expiry time.Time
+	if state == nil {
+		return expiry
+	}
+
+	for _, cert := range state.PeerCertificates {
+		if expiry.IsZero() || cert.NotAfter.Before(expiry) {
+			expiry = cert.NotAfter
+		}
+	}
+
+	return expiry
+}
+
+// parseWarning parses a number of days, optionally with a "d" suffix, or a
+// duration like "72h".
+func parseWarning(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return DefaultExpiryWarning, nil
+	}
+
+	if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid expiry warning '%s', expected days like '14d' or a duration", value)
+	}
+
+	return d, nil
+}
+
+// TLSExpiryChecker fails when a certificate in the chain expires within the
+// warning period.
+type TLSExpiryChecker struct {
+	name    string
+	warning time.Duration
+}
+
+func NewTLSExpiryChecker(name, value string) (*TLSExpiryChecker, error) {
+	warning, err := parseWarning(value)
+	if err != nil {
+		return nil, fmt.Errorf("%v in '%s'", err, name)
+	}
+
+	return &TLSExpiryChecker{
+		name:    name,
+		warning: warning,
+	}, nil
+}
+
+func (c *TLSExpiryChecker) String() string {
+	days := int(c.warning.Hours() / 24)
+	if time.Duration(days)*24*time.Hour == c.warning {
+		return fmt.Sprintf("%s - certificate valid for more than %d days", c.name, days)
+	}
+
+	return fmt.Sprintf("%s - certificate valid for more than %s", c.name, c.warning)
+}
+
+func (c *TLSExpiryChecker) CheckResponse(resp *Response) (bool, error) {
+	if resp.TLS == nil {
+		return false, errNoTLS
+	}
+
+	expiry := CertificateExpiry(resp.TLS)
+	left := time.Until(expiry)
+	if left <= c.warning {
+		return false, fmt.Errorf("certificate expires in %d days, at %s", int(left.Hours()/24), expiry.Format(time.RFC3339))
+	}
+
+	return true, nil
+}
+
+func (c *TLSExpiryChecker) Check(r io.Reader) (bool, error) {
+	return false, errNoTLS
+}
+
+func (c *TLSExpiryChecker) CheckRender(p *rod.Page) (bool, error) {
+	return false, errors.New("certificates aren't available for rendered pages")
+}
+
+func (c *TLSExpiryChecker) Type() string {
+	return "TLSExpiryChecker"
+}
+
+func (c *TLSExpiryChecker) Equal(y *TLSExpiryChecker) bool {
+	return c.name == y.name && c.warning == y.warning
+}
+
+// TLSHostnameChecker fails when the certificate isn't valid for the server
+// name.
+type TLSHostnameChecker struct {
+	name string
+}
+
+func NewTLSHostnameChecker(name string) *TLSHostnameChecker {
+	return &TLSHostnameChecker{
+		name: name,
+	}
+}
+
+func (c *TLSHostnameChecker) String() string {
+	return fmt.Sprintf("%s - certificate matches hostname", c.name)
+}
+
+func (c *TLSHostnameChecker) CheckResponse(resp *Response) (bool, error) {
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return false, errNoTLS
+	}
+
+	if err := resp.TLS.PeerCertificates[0].VerifyHostname(resp.ServerName); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (c *TLSHostnameChecker) Check(r io.Reader) (bool, error) {
+	return false, errNoTLS
+}
+
+func (c *TLSHostnameChecker) CheckRender(p *rod.Page) (bool, error) {
+	return false, errors.New("certificates aren't available for rendered pages")
+}
+
+func (c *TLSHostnameChecker) Type() string {
+	return "TLSHostnameChecker"
+}
+
+func (c *TLSHostnameChecker) Equal(y *TLSHostnameChecker) bool {
+	return c.name == y.name
+}
+
+// TLSChainChecker fails when the certificate chain doesn't verify against
+// the roots, for example when it is self signed, misses an intermediate or
+// has expired.
+type TLSChainChecker struct {
+	name string
+}
+
+func NewTLSChainChecker(name string) *TLSChainChecker {
+	return &TLSChainChecker{
+		name: name,
+	}
+}
+
+func (c *TLSChainChecker) String() string {
+	return fmt.Sprintf("%s - certificate chain is valid", c.name)
+}
+
+func (c *TLSChainChecker) CheckResponse(resp *Response) (bool, error) {
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return false, errNoTLS
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range resp.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := resp.TLS.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         resp.Roots,
+		Intermediates: intermediates,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (c *TLSChainChecker) Check(r io.Reader) (bool, error) {
+	return false, errNoTLS
+}
+
+func (c *TLSChainChecker) CheckRender(p *rod.Page) (bool, error) {
+	return false, errors.New("certificates aren't available for rendered pages")
+}
+
+func (c *TLSChainChecker) Type() string {
+	return "TLSChainChecker"
+}
+
+func (c *TLSChainChecker) Equal(y *TLSChainChecker) bool {
+	return c.name == y.name
+}
+
+// TLSVersionChecker fails when the negotiated protocol version is below the
+// minimum.
+type TLSVersionChecker struct {
+	name string
+	min  uint16
+}
+
+// NewTLSVersionChecker takes the minimum version like "1.2" in value.
+func NewTLSVersionChecker(name, value string) (*TLSVersionChecker, error) {
+	min := uint16(DefaultMinTLSVersion)
+	if value != "" {
+		var ok bool
+		if min, ok = tlsVersions[value]; !ok {
+			return nil, fmt.Errorf("invalid TLS version '%s' in '%s', expected 1.0 to 1.3", value, name)
+		}
+	}
+
+	return &TLSVersionChecker{
+		name: name,
+		min:  min,
+	}, nil
+}
+
+func (c *TLSVersionChecker) String() string {
+	return fmt.Sprintf("%s - TLS %s or newer", c.name, tlsVersionName(c.min))
+}
+
+func (c *TLSVersionChecker) CheckResponse(resp *Response) (bool, error) {
+	if resp.TLS == nil {
+		return false, errNoTLS
+	}
+
+	if resp.TLS.Version < c.min {
+		return false, fmt.Errorf("negotiated TLS %s", tlsVersionName(resp.TLS.Version))
+	}
+
+	return true, nil
+}
+
+func (c *TLSVersionChecker) Check(r io.Reader) (bool, error) {
+	return false, errNoTLS
+}
+
+func (c *TLSVersionChecker) CheckRender(p *rod.Page) (bool, error) {
+	return false, errors.New("TLS versions aren't available for rendered pages")
+}
+
+func (c *TLSVersionChecker) Type() string {
+	return "TLSVersionChecker"
+}
+
+func (c *TLSVersionChecker) Equal(y *TLSVersionChecker) bool {
+	return c.name == y.name && c.min == y.min
+}
+
+// DefaultTLSChecks are used by tls monitors without checks.
+func DefaultTLSChecks() []ContentCheckerHolder {
+	expiry, _ := NewTLSExpiryChecker("expiry", "")
+	version, _ := NewTLSVersionChecker("version", "")
+
+	return []ContentCheckerHolder{
+		{ContentChecker: expiry},
+		{ContentChecker: NewTLSHostnameChecker("hostname")},
+		{ContentChecker: NewTLSChainChecker("chain")},
+		{ContentChecker: version},
+	}
+}
@@ -0,0 +1,137 @@
+package content_checkers_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+	"website-monitor/content_checkers"
+)
+
+// newCert creates a certificate from template, signed by parent or self
+// signed if parent is nil.
+func newCert(t *testing.T, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, key
+}
+
+func testChain(t *testing.T, leafNotAfter time.Time) (*x509.CertPool, []*x509.Certificate) {
+	ca, caKey := newCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	leaf, _ := newCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "shop.example.com"},
+		DNSNames:     []string{"shop.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     leafNotAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	return roots, []*x509.Certificate{leaf}
+}
+
+func TestTLSCheckers_CheckResponse(t *testing.T) {
+	roots, certs := testChain(t, time.Now().Add(30*24*time.Hour))
+	_, soonCerts := testChain(t, time.Now().Add(5*24*time.Hour))
+
+	valid := &content_checkers.Response{
+		TLS:        &tls.ConnectionState{Version: tls.VersionTLS13, PeerCertificates: certs},
+		ServerName: "shop.example.com",
+		Roots:      roots,
+	}
+
+	expiry, _ := content_checkers.NewTLSExpiryChecker("expiry", "14d")
+	longExpiry, _ := content_checkers.NewTLSExpiryChecker("expiry", "60")
+	version, _ := content_checkers.NewTLSVersionChecker("version", "1.2")
+	version13, _ := content_checkers.NewTLSVersionChecker("version", "1.3")
+
+	tests := []struct {
+		name    string
+		checker content_checkers.ResponseChecker
+		resp    *content_checkers.Response
+		result  bool
+	}{
+		{name: "expiry ok", checker: expiry, resp: valid, result: true},
+		{name: "expiry within warning", checker: longExpiry, resp: valid, result: false},
+		{name: "expiring soon", checker: expiry, resp: &content_checkers.Response{TLS: &tls.ConnectionState{PeerCertificates: soonCerts}}, result: false},
+		{name: "hostname ok", checker: content_checkers.NewTLSHostnameChecker("hostname"), resp: valid, result: true},
+		{name: "hostname mismatch", checker: content_checkers.NewTLSHostnameChecker("hostname"), resp: &content_checkers.Response{
+			TLS:        valid.TLS,
+			ServerName: "www.example.com",
+		}, result: false},
+		{name: "chain ok", checker: content_checkers.NewTLSChainChecker("chain"), resp: valid, result: true},
+		{name: "unknown authority", checker: content_checkers.NewTLSChainChecker("chain"), resp: &content_checkers.Response{
+			TLS:   valid.TLS,
+			Roots: x509.NewCertPool(),
+		}, result: false},
+		{name: "version ok", checker: version, resp: valid, result: true},
+		{name: "weak version", checker: version13, resp: &content_checkers.Response{
+			TLS: &tls.ConnectionState{Version: tls.VersionTLS12, PeerCertificates: certs},
+		}, result: false},
+		{name: "no tls", checker: version, resp: &content_checkers.Response{}, result: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := test.checker.CheckResponse(test.resp)
+			if res != test.result {
+				t.Errorf("got %t (err: %v), expected %t", res, err, test.result)
+			}
+			if !res && err == nil {
+				t.Error("got no error, expected the reason the check failed")
+			}
+		})
+	}
+}
+
+func TestCertificateExpiry(t *testing.T) {
+	notAfter := time.Now().Add(10 * 24 * time.Hour).Truncate(time.Second)
+	_, certs := testChain(t, notAfter)
+
+	if got := content_checkers.CertificateExpiry(&tls.ConnectionState{PeerCertificates: certs}); !got.Equal(notAfter) {
+		t.Errorf("got %s, expected %s", got, notAfter)
+	}
+	if got := content_checkers.CertificateExpiry(nil); !got.IsZero() {
+		t.Errorf("got %s, expected zero time without TLS", got)
+	}
+}
+
+func TestNewTLSCheckers_Invalid(t *testing.T) {
+	if _, err := content_checkers.NewTLSExpiryChecker("expiry", "soon"); err == nil {
+		t.Error("got nil, expected an error for an invalid warning")
+	}
+	if _, err := content_checkers.NewTLSVersionChecker("version", "2.0"); err == nil {
+		t.Error("got nil, expected an error for an invalid version")
+	}
+}
@@ -0,0 +1,451 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/hostlimit"
+	"website-monitor/monitors"
+	"website-monitor/prometheus"
+	"website-monitor/state"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultWorkers = 10
+	DefaultTick    = 1 * time.Second
+	// DefaultDrainTimeout leaves some room within the 10 seconds Docker
+	// waits between SIGTERM and SIGKILL.
+	DefaultDrainTimeout = 8 * time.Second
+)
+
+type Options struct {
+	// Workers is the number of checks which can run at once.
+	Workers int
+	// Tick is how often monitors are looked at to see if they are due.
+	Tick time.Duration
+	// Limiter, if set, limits checks per origin.
+	Limiter *hostlimit.Limiter
+	// Store, if set, gets the state of each monitor after every check and
+	// when Run returns.
+	Store *state.Store
+	// DrainTimeout is how long Run waits for running checks to finish once
+	// its context is done, before cancelling them.
+	DrainTimeout time.Duration
+}
+
+// Engine dispatches due monitors to a fixed pool of workers.
+//
+// A monitor is only touched by the dispatcher while it isn't running, and
+// only by the one worker running it while it is, with the hand over between
+// them guarded by mu. This keeps access to the monitors' status race free
+// without locking inside monitors.Monitor, and guarantees a monitor never
+// runs twice at once.
+type Engine struct {
+	opts   Options
+	queue  chan *monitors.Monitor
+	policy monitors.StartupPolicy
+
+	mu       sync.Mutex
+	monitors []*monitors.Monitor
+	// configured holds the monitors in monitors, so monitors dropped by
+	// Reload while dispatch still holds them aren't claimed.
+	configured map[*monitors.Monitor]bool
+	running    map[*monitors.Monitor]bool
+	// replaced maps monitors which were changed by Reload while running to
+	// the monitors replacing them, which take over once the check is done.
+	replaced map[*monitors.Monitor]*monitors.Monitor
+	// removed holds monitors which were removed by Reload while running.
+	removed map[*monitors.Monitor]bool
+}
+
+func New(ms []*monitors.Monitor, opts Options) *Engine {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.Tick <= 0 {
+		opts.Tick = DefaultTick
+	}
+	if opts.DrainTimeout <= 0 {
+		opts.DrainTimeout = DefaultDrainTimeout
+	}
+
+	return &Engine{
+		monitors:   ms,
+		opts:       opts,
+		queue:      make(chan *monitors.Monitor, opts.Workers),
+		configured: configured(ms),
+		running:    make(map[*monitors.Monitor]bool),
+		replaced:   make(map[*monitors.Monitor]*monitors.Monitor),
+		removed:    make(map[*monitors.Monitor]bool),
+	}
+}
+
+func configured(ms []*monitors.Monitor) map[*monitors.Monitor]bool {
+	set := make(map[*monitors.Monitor]bool, len(ms))
+	for _, m := range ms {
+		set[m] = true
+	}
+
+	return set
+}
+
+// Start sets when each monitor is first checked according to policy, using
+// saved state from the store if there is one, and initializes its metrics.
+// It must be called before Run.
+func (e *Engine) Start(policy monitors.StartupPolicy) {
+	e.policy = policy
+
+	now := time.Now()
+	for _, m := range e.monitors {
+		e.start(m, now)
+	}
+}
+
+func (e *Engine) start(m *monitors.Monitor, now time.Time) {
+	var saved *state.MonitorState
+	if e.opts.Store != nil {
+		if st, ok := e.opts.Store.Get(m.Name); ok {
+			saved = &st
+		}
+	}
+	m.Start(e.policy, now, saved)
+
+	initMetrics(m)
+}
+
+// Reload replaces the monitors with ms, matching them by name. Unchanged
+// monitors are kept as they are, so their status and timing carry over.
+// Changed monitors keep their last seen state and are checked right away.
+// Added monitors are started like at startup. Removed monitors are dropped
+// along with their metrics and saved state. A monitor which is running while
+// it is changed or removed finishes its check first.
+func (e *Engine) Reload(ms []*monitors.Monitor) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	old := make(map[string]*monitors.Monitor, len(e.monitors))
+	for _, m := range e.monitors {
+		old[m.Name] = m
+	}
+
+	now := time.Now()
+	next := make([]*monitors.Monitor, 0, len(ms))
+	for _, m := range ms {
+		prev, ok := old[m.Name]
+		delete(old, m.Name)
+
+		switch {
+		case !ok:
+			log.Infof("Adding %s", m.Name)
+			e.start(m, now)
+		case prev.Equal(m):
+			m = prev
+		case e.running[prev]:
+			log.Infof("Updating %s once its running check is done", m.Name)
+			// Keep the new monitor from being claimed until it has taken
+			// over from the running one.
+			e.replaced[e.pending(prev)] = m
+			e.running[m] = true
+		default:
+			log.Infof("Updating %s", m.Name)
+			takeOver(prev, m, now)
+		}
+		next = append(next, m)
+	}
+
+	for _, m := range old {
+		log.Infof("Removing %s", m.Name)
+		if e.running[m] {
+			e.removed[e.pending(m)] = true
+			continue
+		}
+		e.forget(m)
+	}
+
+	e.monitors = next
+	e.configured = configured(next)
+}
+
+// pending returns the running monitor m is waiting to take over from, and
+// drops m as its replacement. A monitor which isn't a replacement is returned
+// as it is. This way a monitor changed or removed again before its running
+// check is done only ever has one replacement.
+func (e *Engine) pending(m *monitors.Monitor) *monitors.Monitor {
+	for orig, next := range e.replaced {
+		if next == m {
+			delete(e.replaced, orig)
+			delete(e.running, m)
+			return orig
+		}
+	}
+
+	return m
+}
+
+// takeOver moves the status of a changed monitor prev to the monitor m
+// replacing it, and makes m due at now.
+func takeOver(prev, m *monitors.Monitor, now time.Time) {
+	st := prev.State()
+	st.NextCheckAt = now
+	m.Restore(st)
+
+	initMetrics(m)
+}
+
+// forget deletes the metrics and saved state of a removed monitor.
+func (e *Engine) forget(m *monitors.Monitor) {
+	var phases []string
+	for _, phase := range content_checkers.Phases {
+		phases = append(phases, string(phase))
+	}
+	var stats []string
+	for _, stat := range content_checkers.LatencyStats {
+		stats = append(stats, string(stat))
+	}
+	prometheus.DeleteMonitor(m.Name, phases, stats)
+	if e.opts.Store != nil {
+		e.opts.Store.Delete(m.Name)
+	}
+}
+
+// Run dispatches monitors until ctx is done. It then stops dispatching,
+// gives running checks up to the drain timeout to finish before cancelling
+// them, and saves the state of all monitors before returning. Run must only
+// be called once.
+func (e *Engine) Run(ctx context.Context) {
+	// Checks get their own context, so they aren't cancelled as soon as ctx
+	// is done but only once the drain timeout has passed.
+	checkCtx, cancelChecks := context.WithCancel(context.Background())
+	defer cancelChecks()
+
+	var wg sync.WaitGroup
+	for i := 0; i < e.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range e.queue {
+				if ctx.Err() != nil {
+					// Shutting down, don't start checks which were still queued.
+					prometheus.JobQueueGauge.Dec()
+					e.release(m)
+					continue
+				}
+				e.process(checkCtx, m)
+			}
+		}()
+	}
+
+	t := time.NewTicker(e.opts.Tick)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			close(e.queue)
+			e.drain(&wg, cancelChecks)
+			e.flush()
+			return
+		case <-t.C:
+			e.dispatch(ctx)
+		}
+	}
+}
+
+func (e *Engine) drain(wg *sync.WaitGroup, cancelChecks context.CancelFunc) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(e.opts.DrainTimeout):
+		log.Warnf("Cancelling checks still running after %s", e.opts.DrainTimeout)
+		cancelChecks()
+		<-done
+	}
+}
+
+// flush saves the state of all monitors. It must only be called once no
+// checks are running.
+func (e *Engine) flush() {
+	if e.opts.Store == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, m := range e.monitors {
+		e.opts.Store.Set(m.Name, m.State())
+	}
+	if err := e.opts.Store.Save(); err != nil {
+		log.Errorf("Error while saving state: %s", err)
+	}
+}
+
+func (e *Engine) dispatch(ctx context.Context) {
+	log.Debug("Looking for job...")
+	e.mu.Lock()
+	ms := e.monitors
+	e.mu.Unlock()
+
+	for _, m := range ms {
+		if !e.claim(m) {
+			continue
+		}
+
+		log.Infof("Queuing %s...", m.Name)
+		prometheus.JobQueueGauge.Inc()
+		select {
+		case e.queue <- m:
+		case <-ctx.Done():
+			prometheus.JobQueueGauge.Dec()
+			e.release(m)
+			return
+		}
+	}
+}
+
+// claim marks m as running if it is still configured, due and allowed to
+// run.
+func (e *Engine) claim(m *monitors.Monitor) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running[m] || !e.configured[m] {
+		return false
+	}
+
+	if m.ActiveMaintenance(time.Now()) != nil {
+		prometheus.MonitorsInMaintenance.WithLabelValues(m.Name).Set(1)
+	} else {
+		prometheus.MonitorsInMaintenance.WithLabelValues(m.Name).Set(0)
+	}
+
+	if !m.ShouldUpdate() {
+		return false
+	}
+
+	if e.opts.Limiter != nil && !e.opts.Limiter.TryAcquire(m.Origin(), time.Now()) {
+		log.Debugf("Delaying %s, limit reached for %s", m.Name, m.Origin())
+		return false
+	}
+
+	m.CheckPending = true
+	e.running[m] = true
+
+	return true
+}
+
+func (e *Engine) release(m *monitors.Monitor) {
+	if e.opts.Limiter != nil {
+		e.opts.Limiter.Release(m.Origin())
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m.CheckPending = false
+	delete(e.running, m)
+
+	if next, ok := e.replaced[m]; ok {
+		delete(e.replaced, m)
+		delete(e.running, next)
+		takeOver(m, next, time.Now())
+	}
+	if e.removed[m] {
+		delete(e.removed, m)
+		e.forget(m)
+	}
+}
+
+func (e *Engine) process(ctx context.Context, m *monitors.Monitor) {
+	defer e.release(m)
+
+	prometheus.JobQueueGauge.Dec()
+	prometheus.MonitorsProcessedTotal.Inc()
+	prometheus.MonitorsIndividualProcessed.WithLabelValues(m.Name).Inc()
+	if err := m.Run(ctx); err != nil {
+		prometheus.MonitorsErroredTotal.Inc()
+		prometheus.MonitorsIndividualErrored.WithLabelValues(m.Name).Inc()
+		log.Errorf("Error in %s: %v", m.Name, err)
+	}
+	setLastSeenState(m)
+	observeTimings(m)
+	setTLSExpiry(m)
+	setProbes(m)
+	prometheus.MonitorsNextCheckInfo.WithLabelValues(m.Name).Set(float64(m.NextCheckAt().Unix()))
+
+	if e.opts.Store != nil {
+		e.opts.Store.Set(m.Name, m.State())
+		if err := e.opts.Store.Save(); err != nil {
+			log.Errorf("Error while saving state: %s", err)
+		}
+	}
+}
+
+func initMetrics(m *monitors.Monitor) {
+	setLastSeenState(m)
+	prometheus.MonitorsIndividualProcessed.WithLabelValues(m.Name).Add(0)
+	prometheus.MonitorsIndividualErrored.WithLabelValues(m.Name).Add(0)
+	prometheus.MonitorsNextCheckInfo.WithLabelValues(m.Name).Set(float64(m.NextCheckAt().Unix()))
+	prometheus.MonitorsInMaintenance.WithLabelValues(m.Name).Set(0)
+}
+
+// observeTimings records the response times of the last check, skipping
+// phases which didn't happen.
+func observeTimings(m *monitors.Monitor) {
+	res := m.LastResults()
+	if res == nil {
+		return
+	}
+
+	for _, phase := range content_checkers.Phases {
+		if d, _ := res.Timings.Get(phase); d > 0 {
+			prometheus.MonitorsResponseTime.WithLabelValues(m.Name, string(phase)).Observe(d.Seconds())
+		}
+	}
+}
+
+func setTLSExpiry(m *monitors.Monitor) {
+	res := m.LastResults()
+	if res == nil || res.CertificateExpiry.IsZero() {
+		return
+	}
+
+	prometheus.MonitorsTLSExpiry.WithLabelValues(m.Name).Set(time.Until(res.CertificateExpiry).Seconds())
+}
+
+// setProbes exports the loss and latencies of reachability monitors. The
+// latencies are left as they were when no probe was received.
+func setProbes(m *monitors.Monitor) {
+	res := m.LastResults()
+	if res == nil || res.Probes == nil {
+		return
+	}
+
+	p := res.Probes
+	prometheus.MonitorsReachabilityLoss.WithLabelValues(m.Name).Set(p.Loss())
+	prometheus.MonitorsReachabilityProbes.WithLabelValues(m.Name, "true").Add(float64(p.Received))
+	prometheus.MonitorsReachabilityProbes.WithLabelValues(m.Name, "false").Add(float64(p.Sent - p.Received))
+	if p.Received == 0 {
+		return
+	}
+	for _, stat := range content_checkers.LatencyStats {
+		d, _ := p.Latency(stat)
+		prometheus.MonitorsReachabilityLatency.WithLabelValues(m.Name, string(stat)).Set(d.Seconds())
+	}
+}
+
+func setLastSeenState(m *monitors.Monitor) {
+	if m.LastSeenState {
+		prometheus.LastSeenState.WithLabelValues(m.Name).Set(1)
+	} else {
+		prometheus.LastSeenState.WithLabelValues(m.Name).Set(0)
+	}
+}
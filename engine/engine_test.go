@@ -0,0 +1,464 @@
+package engine_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	log "github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/engine"
+	"website-monitor/hostlimit"
+	"website-monitor/monitors"
+	"website-monitor/prometheus"
+	"website-monitor/scheduler"
+	"website-monitor/state"
+)
+
+// concurrencyServer counts requests per path and records the highest number
+// of requests in flight, in total and per path.
+type concurrencyServer struct {
+	mu          sync.Mutex
+	inFlight    map[string]int
+	maxPerPath  map[string]int
+	requests    map[string]int
+	total       int
+	maxTotal    int
+	handlerWait time.Duration
+}
+
+func newConcurrencyServer(wait time.Duration) *concurrencyServer {
+	return &concurrencyServer{
+		inFlight:    make(map[string]int),
+		maxPerPath:  make(map[string]int),
+		requests:    make(map[string]int),
+		handlerWait: wait,
+	}
+}
+
+func (s *concurrencyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.inFlight[r.URL.Path]++
+	s.requests[r.URL.Path]++
+	s.total++
+	if s.inFlight[r.URL.Path] > s.maxPerPath[r.URL.Path] {
+		s.maxPerPath[r.URL.Path] = s.inFlight[r.URL.Path]
+	}
+	if s.total > s.maxTotal {
+		s.maxTotal = s.total
+	}
+	s.mu.Unlock()
+
+	time.Sleep(s.handlerWait)
+	_, _ = fmt.Fprintln(w, "This is some sort of text.")
+
+	s.mu.Lock()
+	s.inFlight[r.URL.Path]--
+	s.total--
+	s.mu.Unlock()
+}
+
+func testMonitors(url string, count int) []*monitors.Monitor {
+	intZero := 0
+	var ms []*monitors.Monitor
+	for i := 0; i < count; i++ {
+		ms = append(ms, &monitors.Monitor{
+			Name:               fmt.Sprintf("monitor %d", i),
+			Url:                fmt.Sprintf("%s/%d", url, i),
+			ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+			ContentChecks: []content_checkers.ContentCheckerHolder{
+				{ContentChecker: content_checkers.NewRegexChecker("regex", "sort of text", true)},
+			},
+			Scheduler: scheduler.NewScheduler(time.Millisecond, &intZero, nil, nil),
+		})
+	}
+
+	return ms
+}
+
+func runFor(e *engine.Engine, d time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	e.Run(ctx)
+}
+
+func TestEngine_Run(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+
+	srv := newConcurrencyServer(20 * time.Millisecond)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	ms := testMonitors(ts.URL, 5)
+	e := engine.New(ms, engine.Options{Workers: 2, Tick: time.Millisecond})
+	e.Start(monitors.ImmediateStartup)
+	runFor(e, 500*time.Millisecond)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if srv.maxTotal > 2 {
+		t.Errorf("got %d requests at once, expected at most %d workers", srv.maxTotal, 2)
+	}
+	if srv.total != 0 {
+		t.Errorf("got %d requests in flight after Run returned, expected 0", srv.total)
+	}
+	for _, m := range ms {
+		path := m.Url[len(ts.URL):]
+		if srv.maxPerPath[path] > 1 {
+			t.Errorf("%s ran %d times at once, expected at most once", m.Name, srv.maxPerPath[path])
+		}
+		if srv.requests[path] < 2 {
+			t.Errorf("%s ran %d times, expected it to be checked repeatedly", m.Name, srv.requests[path])
+		}
+		if !m.LastSeenState {
+			t.Errorf("%s: expected last seen state to be true", m.Name)
+		}
+		if !prometheus.MonitorsResponseTime.DeleteLabelValues(m.Name, "total") {
+			t.Errorf("%s: expected the total response time to be observed", m.Name)
+		}
+	}
+}
+
+func TestEngine_RunWithLimiter(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+
+	srv := newConcurrencyServer(20 * time.Millisecond)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	ms := testMonitors(ts.URL, 5)
+	e := engine.New(ms, engine.Options{
+		Workers: 5,
+		Tick:    time.Millisecond,
+		Limiter: hostlimit.NewLimiter(1, 0),
+	})
+	e.Start(monitors.ImmediateStartup)
+	runFor(e, 300*time.Millisecond)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if srv.maxTotal != 1 {
+		t.Errorf("got %d requests at once against one host, expected 1", srv.maxTotal)
+	}
+}
+
+func TestEngine_RunDrainsRunningChecks(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+
+	srv := newConcurrencyServer(200 * time.Millisecond)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "engine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := state.NewStore(filepath.Join(dir, "state.json"))
+
+	ms := testMonitors(ts.URL, 1)
+	ms[0].Scheduler = scheduler.NewScheduler(time.Hour, nil, nil, nil)
+	e := engine.New(ms, engine.Options{Workers: 1, Tick: time.Millisecond, Store: store})
+	e.Start(monitors.ImmediateStartup)
+
+	started := time.Now()
+	runFor(e, 50*time.Millisecond)
+
+	if time.Since(started) < 200*time.Millisecond {
+		t.Errorf("Run returned after %s, expected it to wait for the running check", time.Since(started))
+	}
+	if !ms[0].LastSeenState {
+		t.Error("expected the running check to complete")
+	}
+
+	loaded := state.NewStore(filepath.Join(dir, "state.json"))
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("got err %v, expected nil", err)
+	}
+	if st, ok := loaded.Get(ms[0].Name); !ok || !st.LastSeenState {
+		t.Errorf("got state %+v, expected it to be flushed", st)
+	}
+}
+
+func TestEngine_RunCancelsChecksAfterDrainTimeout(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	ms := testMonitors(ts.URL, 1)
+	ms[0].Scheduler = scheduler.NewScheduler(time.Hour, nil, nil, nil)
+	e := engine.New(ms, engine.Options{Workers: 1, Tick: time.Millisecond, DrainTimeout: 100 * time.Millisecond})
+	e.Start(monitors.ImmediateStartup)
+
+	started := time.Now()
+	runFor(e, 50*time.Millisecond)
+
+	if time.Since(started) > 2*time.Second {
+		t.Errorf("Run returned after %s, expected the check to be cancelled after the drain timeout", time.Since(started))
+	}
+	if ms[0].LastSeenState {
+		t.Error("expected the cancelled check to not change state")
+	}
+}
+
+func TestEngine_Reload(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+
+	srv := newConcurrencyServer(0)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	ms := testMonitors(ts.URL, 3)
+	for _, m := range ms {
+		m.Scheduler = scheduler.NewScheduler(time.Hour, nil, nil, nil)
+	}
+	e := engine.New(ms, engine.Options{Workers: 2, Tick: time.Millisecond})
+	e.Start(monitors.ImmediateStartup)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// monitor 0 is unchanged, monitor 1 is changed, monitor 2 is removed
+	// and monitor 3 is added.
+	reloaded := testMonitors(ts.URL, 4)
+	for _, m := range reloaded {
+		m.Scheduler = scheduler.NewScheduler(time.Hour, nil, nil, nil)
+	}
+	reloaded[1].ContentChecks = append(reloaded[1].ContentChecks,
+		content_checkers.ContentCheckerHolder{ContentChecker: content_checkers.NewRegexChecker("other", "text", true)})
+	reloaded = append(reloaded[:2], reloaded[3])
+	e.Reload(reloaded)
+	time.Sleep(100 * time.Millisecond)
+
+	cancel()
+	<-done
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	for path, expected := range map[string]int{"/0": 1, "/1": 2, "/2": 1, "/3": 1} {
+		if srv.requests[path] != expected {
+			t.Errorf("%s: got %d requests, expected %d", path, srv.requests[path], expected)
+		}
+	}
+	if !reloaded[1].LastSeenState {
+		t.Error("expected the changed monitor to keep its last seen state")
+	}
+	if prometheus.LastSeenState.DeleteLabelValues("monitor 2") {
+		t.Error("expected the series of the removed monitor to be deleted")
+	}
+	if !prometheus.LastSeenState.DeleteLabelValues("monitor 3") {
+		t.Error("expected the series of the added monitor to exist")
+	}
+}
+
+func TestEngine_ReloadWhileRunning(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+
+	srv := newConcurrencyServer(100 * time.Millisecond)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	ms := testMonitors(ts.URL, 1)
+	ms[0].Scheduler = scheduler.NewScheduler(time.Hour, nil, nil, nil)
+	e := engine.New(ms, engine.Options{Workers: 2, Tick: time.Millisecond})
+	e.Start(monitors.ImmediateStartup)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	reloaded := testMonitors(ts.URL, 1)
+	reloaded[0].Scheduler = scheduler.NewScheduler(2*time.Hour, nil, nil, nil)
+	e.Reload(reloaded)
+	time.Sleep(300 * time.Millisecond)
+
+	cancel()
+	<-done
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if srv.maxPerPath["/0"] != 1 {
+		t.Errorf("got %d checks at once, expected the changed monitor to wait for the running one", srv.maxPerPath["/0"])
+	}
+	if srv.requests["/0"] != 2 {
+		t.Errorf("got %d requests, expected 2", srv.requests["/0"])
+	}
+	if !reloaded[0].LastSeenState {
+		t.Error("expected the changed monitor to take over the last seen state")
+	}
+}
+
+func TestEngine_ReloadTwiceWhileRunning(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+
+	tests := []struct {
+		name     string
+		second   bool
+		requests int
+	}{
+		{"changed twice", true, 2},
+		{"changed then removed", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newConcurrencyServer(100 * time.Millisecond)
+			ts := httptest.NewServer(srv)
+			defer ts.Close()
+
+			ms := testMonitors(ts.URL, 1)
+			ms[0].Scheduler = scheduler.NewScheduler(time.Hour, nil, nil, nil)
+			e := engine.New(ms, engine.Options{Workers: 2, Tick: time.Millisecond})
+			e.Start(monitors.ImmediateStartup)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				e.Run(ctx)
+				close(done)
+			}()
+			time.Sleep(20 * time.Millisecond)
+
+			first := testMonitors(ts.URL, 1)
+			first[0].Scheduler = scheduler.NewScheduler(2*time.Hour, nil, nil, nil)
+			e.Reload(first)
+
+			var second []*monitors.Monitor
+			if tt.second {
+				second = testMonitors(ts.URL, 1)
+				second[0].Scheduler = scheduler.NewScheduler(3*time.Hour, nil, nil, nil)
+			}
+			e.Reload(second)
+			time.Sleep(300 * time.Millisecond)
+
+			cancel()
+			<-done
+
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+
+			if srv.maxPerPath["/0"] != 1 {
+				t.Errorf("got %d checks at once, expected 1", srv.maxPerPath["/0"])
+			}
+			if srv.requests["/0"] != tt.requests {
+				t.Errorf("got %d requests, expected %d", srv.requests["/0"], tt.requests)
+			}
+			if first[0].LastResults() != nil {
+				t.Error("expected the first replacement to never run")
+			}
+			if tt.second {
+				if !second[0].LastSeenState {
+					t.Error("expected the last replacement to take over the last seen state")
+				}
+				return
+			}
+			if prometheus.LastSeenState.DeleteLabelValues(ms[0].Name) {
+				t.Error("expected the metrics of the removed monitor to be deleted")
+			}
+		})
+	}
+}
+
+func TestEngine_ReloadDuringBlockedDispatch(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+
+	srv := newConcurrencyServer(100 * time.Millisecond)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	// With one worker, dispatch blocks queuing the third monitor until the
+	// first check is done, and hasn't claimed the fourth yet.
+	ms := testMonitors(ts.URL, 4)
+	e := engine.New(ms, engine.Options{Workers: 1, Tick: time.Millisecond})
+	e.Start(monitors.ImmediateStartup)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	e.Reload(ms[:3])
+	time.Sleep(300 * time.Millisecond)
+
+	cancel()
+	<-done
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if srv.requests["/3"] != 0 {
+		t.Errorf("got %d requests, expected the removed monitor to never run", srv.requests["/3"])
+	}
+	if prometheus.LastSeenState.DeleteLabelValues(ms[3].Name) {
+		t.Error("expected the metrics of the removed monitor to stay deleted")
+	}
+}
+
+func TestEngine_RunReachability(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	intZero := 0
+	m := &monitors.Monitor{
+		Name:          "reachability",
+		Url:           ts.Listener.Addr().String(),
+		Type:          monitors.ReachabilityMonitorType,
+		ProbeCount:    2,
+		ProbeInterval: time.Millisecond,
+		Scheduler:     scheduler.NewScheduler(time.Hour, &intZero, nil, nil),
+	}
+	e := engine.New([]*monitors.Monitor{m}, engine.Options{Workers: 1, Tick: time.Millisecond})
+	e.Start(monitors.ImmediateStartup)
+	runFor(e, 200*time.Millisecond)
+
+	if loss := testutil.ToFloat64(prometheus.MonitorsReachabilityLoss.WithLabelValues(m.Name)); loss != 0 {
+		t.Errorf("got loss %f, expected 0", loss)
+	}
+	if received := testutil.ToFloat64(prometheus.MonitorsReachabilityProbes.WithLabelValues(m.Name, "true")); received != 2 {
+		t.Errorf("got %f received probes, expected 2", received)
+	}
+	if max := testutil.ToFloat64(prometheus.MonitorsReachabilityLatency.WithLabelValues(m.Name, "max")); max <= 0 {
+		t.Errorf("got max latency %f, expected it to be set", max)
+	}
+
+	e.Reload(nil)
+	if prometheus.MonitorsReachabilityLoss.DeleteLabelValues(m.Name) {
+		t.Errorf("expected the loss to be deleted with the monitor")
+	}
+	if prometheus.MonitorsReachabilityLatency.DeleteLabelValues(m.Name, "max") {
+		t.Errorf("expected the latencies to be deleted with the monitor")
+	}
+}
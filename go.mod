@@ -8,8 +8,12 @@ require (
 	github.com/go-pg/pg/v10 v10.9.0
 	github.com/go-rod/rod v0.91.1
 	github.com/google/go-cmp v0.5.5
+	github.com/gorilla/websocket v1.4.2
 	github.com/prometheus/client_golang v1.9.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/sirupsen/logrus v1.7.0
+	golang.org/x/net v0.0.0-20210226172049-e18ecbb05110
+	google.golang.org/grpc v1.43.0
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
@@ -0,0 +1,61 @@
+package hostlimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter limits how many checks run against the same origin at once, and
+// how close together checks against the same origin may start.
+type Limiter struct {
+	maxConcurrent int
+	minSpacing    time.Duration
+
+	mu        sync.Mutex
+	running   map[string]int
+	lastStart map[string]time.Time
+}
+
+// NewLimiter returns a Limiter allowing maxConcurrent checks per origin at
+// once, started at least minSpacing apart. Zero disables either limit.
+func NewLimiter(maxConcurrent int, minSpacing time.Duration) *Limiter {
+	return &Limiter{
+		maxConcurrent: maxConcurrent,
+		minSpacing:    minSpacing,
+		running:       make(map[string]int),
+		lastStart:     make(map[string]time.Time),
+	}
+}
+
+// TryAcquire reserves a slot for a check against origin starting at now. It
+// never blocks, a caller which gets false should try again later. Every
+// successful TryAcquire must be followed by a Release.
+func (l *Limiter) TryAcquire(origin string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxConcurrent > 0 && l.running[origin] >= l.maxConcurrent {
+		return false
+	}
+
+	if last, ok := l.lastStart[origin]; ok && l.minSpacing > 0 && now.Sub(last) < l.minSpacing {
+		return false
+	}
+
+	l.running[origin]++
+	l.lastStart[origin] = now
+
+	return true
+}
+
+func (l *Limiter) Release(origin string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running[origin] <= 1 {
+		delete(l.running, origin)
+		return
+	}
+
+	l.running[origin]--
+}
@@ -0,0 +1,58 @@
+package hostlimit_test
+
+import (
+	"testing"
+	"time"
+	"website-monitor/hostlimit"
+)
+
+func TestLimiter_MaxConcurrent(t *testing.T) {
+	l := hostlimit.NewLimiter(2, 0)
+	now := time.Now()
+
+	if !l.TryAcquire("https://a:443", now) || !l.TryAcquire("https://a:443", now) {
+		t.Fatal("expected two concurrent checks to be allowed")
+	}
+	if l.TryAcquire("https://a:443", now) {
+		t.Error("expected third concurrent check to be refused")
+	}
+	if !l.TryAcquire("https://b:443", now) {
+		t.Error("expected other origin to be allowed")
+	}
+
+	l.Release("https://a:443")
+	if !l.TryAcquire("https://a:443", now) {
+		t.Error("expected check to be allowed after release")
+	}
+}
+
+func TestLimiter_MinSpacing(t *testing.T) {
+	l := hostlimit.NewLimiter(0, 2*time.Second)
+	now := time.Now()
+
+	if !l.TryAcquire("https://a:443", now) {
+		t.Fatal("expected first check to be allowed")
+	}
+	l.Release("https://a:443")
+
+	if l.TryAcquire("https://a:443", now.Add(time.Second)) {
+		t.Error("expected check within spacing to be refused")
+	}
+	if !l.TryAcquire("https://b:443", now.Add(time.Second)) {
+		t.Error("expected other origin to be allowed")
+	}
+	if !l.TryAcquire("https://a:443", now.Add(2*time.Second)) {
+		t.Error("expected check after spacing to be allowed")
+	}
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := hostlimit.NewLimiter(0, 0)
+	now := time.Now()
+
+	for i := 0; i < 100; i++ {
+		if !l.TryAcquire("https://a:443", now) {
+			t.Fatalf("expected check %d to be allowed", i)
+		}
+	}
+}
@@ -1,15 +1,52 @@
 package main
 
 import (
+	"context"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
+	_ "time/tzdata" // the alpine image has no zoneinfo for schedule timezones
 	"website-monitor/app"
-	"website-monitor/monitors"
+	"website-monitor/engine"
+	"website-monitor/hostlimit"
 	"website-monitor/prometheus"
+	"website-monitor/state"
 )
 
+const configFile = "config/config.yaml"
+
+func setLogLevel(level string) {
+	switch level {
+	case "debug":
+		log.SetLevel(log.DebugLevel)
+	case "warn":
+		log.SetLevel(log.WarnLevel)
+	case "error":
+		log.SetLevel(log.ErrorLevel)
+	default:
+		log.SetLevel(log.InfoLevel)
+	}
+}
+
+// reload loads the config again and hands its monitors to the engine. Only
+// the log level, defaults, maintenance and monitors are reloaded, the other
+// settings need a restart.
+func reload(e *engine.Engine) {
+	config := &app.Config{}
+	if err := config.LoadConfigFromFile(configFile); err != nil {
+		log.Errorf("Error while reloading %s, keeping the current config: %s", configFile, err)
+		return
+	}
+
+	setLogLevel(config.LogLevel)
+	log.Infof("Reloading %d checks...", len(config.Monitors))
+	e.Reload(config.Monitors)
+}
+
 func main() {
 	prometheus.Init()
 
@@ -17,77 +54,79 @@ func main() {
 	log.SetLevel(log.InfoLevel)
 
 	config := &app.Config{}
-	err := config.LoadConfigFromFile("config/config.yaml")
+	err := config.LoadConfigFromFile(configFile)
 	if err != nil {
-		log.Fatalf("Error while loading config/config.yaml: %s", err)
+		log.Fatalf("Error while loading %s: %s", configFile, err)
 	}
 
-	switch config.LogLevel {
-	case "debug":
-		log.SetLevel(log.DebugLevel)
-	case "warn":
-		log.SetLevel(log.WarnLevel)
-	case "error":
-		log.SetLevel(log.ErrorLevel)
-	default:
-		log.SetLevel(log.InfoLevel)
+	setLogLevel(config.LogLevel)
+
+	var store *state.Store
+	if config.StateFile != "" {
+		store = state.NewStore(config.StateFile)
+		if err := store.Load(); err != nil {
+			log.Errorf("Error while loading state from %s: %s", config.StateFile, err)
+		}
 	}
 
-	checks := config.Monitors
-	for _, m := range config.Monitors {
-		prometheus.LastSeenState.WithLabelValues(m.Name).Set(0)
-		prometheus.MonitorsIndividualProcessed.WithLabelValues(m.Name).Add(0)
-		prometheus.MonitorsIndividualErrored.WithLabelValues(m.Name).Add(0)
-		prometheus.MonitorsNextCheckInfo.WithLabelValues(m.Name).Set(float64(m.NextCheckAt().Unix()))
+	var limiter *hostlimit.Limiter
+	if config.Hosts.MaxConcurrent > 0 || config.Hosts.MinSpacing > 0 {
+		limiter = hostlimit.NewLimiter(config.Hosts.MaxConcurrent, config.Hosts.MinSpacing)
 	}
 
-	log.Infof("Starting %d checks...", len(checks))
+	e := engine.New(config.Monitors, engine.Options{
+		Workers:      config.Workers,
+		Limiter:      limiter,
+		Store:        store,
+		DrainTimeout: config.ShutdownTimeout,
+	})
+	e.Start(config.Startup)
 
-	queue := make(chan *monitors.Monitor, 10)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	log.Infof("Starting %d checks...", len(config.Monitors))
 	go func() {
-		t := time.NewTimer(1 * time.Second)
-		for {
-			select {
-			case <-t.C:
-				log.Debug("Looking for job...")
-				for _, c := range checks {
-					if c.ShouldUpdate() {
-						log.Infof("Queuing %s...", c.Name)
-						c.CheckPending = true
-						queue <- c
-						prometheus.JobQueueGauge.Inc()
-					}
-				}
-				t.Reset(1 * time.Second)
-			}
-		}
+		e.Run(ctx)
+		close(done)
 	}()
 
+	http.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{Addr: ":2112"}
 	go func() {
-		for {
-			select {
-			case c := <-queue:
-				prometheus.JobQueueGauge.Dec()
-				go func(ch *monitors.Monitor) {
-					prometheus.MonitorsProcessedTotal.Inc()
-					prometheus.MonitorsIndividualProcessed.WithLabelValues(ch.Name).Inc()
-					if err := ch.Run(); err != nil {
-						prometheus.MonitorsErroredTotal.Inc()
-						log.Errorf("Error in %s: %v", ch.Name, err)
-					}
-					if ch.LastSeenState {
-						prometheus.LastSeenState.WithLabelValues(ch.Name).Set(1)
-					} else {
-						prometheus.LastSeenState.WithLabelValues(ch.Name).Set(0)
-					}
-					prometheus.MonitorsNextCheckInfo.WithLabelValues(ch.Name).Set(float64(ch.NextCheckAt().Unix()))
-				}(c)
-			}
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
 		}
 	}()
 
-	http.Handle("/metrics", promhttp.Handler())
-	log.Fatal(http.ListenAndServe(":2112", nil))
+	if config.ReloadInterval > 0 {
+		go app.WatchFile(ctx, configFile, config.ReloadInterval, func() {
+			log.Infof("%s changed", configFile)
+			reload(e)
+		})
+	}
+
+	for sig := range signals {
+		if sig == syscall.SIGHUP {
+			log.Info("Received SIGHUP")
+			reload(e)
+			continue
+		}
+
+		log.Infof("Received %s, waiting for running checks to finish...", sig)
+		break
+	}
+	cancel()
+	<-done
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Warnf("Error while stopping metrics server: %s", err)
+	}
 
+	log.Info("Stopped")
 }
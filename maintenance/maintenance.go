@@ -0,0 +1,184 @@
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"website-monitor/scheduler"
+)
+
+type Mode string
+
+const (
+	// SkipChecksMode doesn't run checks at all during the window.
+	SkipChecksMode Mode = "skip_checks"
+	// MuteNotificationsMode runs checks, but doesn't send notifications.
+	MuteNotificationsMode Mode = "mute_notifications"
+)
+
+// timeLayouts are tried in order when parsing absolute from and to times.
+// Layouts without a zone are in the window's timezone.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Window is a period of planned downtime, either an absolute range between
+// From and To or a recurring scheduler.Window.
+type Window struct {
+	Name      string
+	Mode      Mode
+	From      time.Time
+	To        time.Time
+	Recurring *scheduler.Window
+	Timezone  string
+
+	location *time.Location
+}
+
+func (w *Window) Location() *time.Location {
+	if w.location == nil {
+		return time.UTC
+	}
+
+	return w.location
+}
+
+// IsActive reports whether t is within the window.
+func (w *Window) IsActive(t time.Time) bool {
+	if w.Recurring != nil {
+		return w.Recurring.Contains(t.In(w.Location()))
+	}
+
+	return !t.Before(w.From) && t.Before(w.To)
+}
+
+func (w *Window) String() string {
+	if w.Recurring != nil {
+		return fmt.Sprintf("%s (%s, %s)", w.Name, w.Recurring, w.Mode)
+	}
+
+	return fmt.Sprintf("%s (%s - %s, %s)", w.Name, w.From.Format(time.RFC3339), w.To.Format(time.RFC3339), w.Mode)
+}
+
+func (w *Window) Equal(y *Window) bool {
+	if w.Name != y.Name || w.Mode != y.Mode || w.Timezone != y.Timezone {
+		return false
+	}
+	if !w.From.Equal(y.From) || !w.To.Equal(y.To) {
+		return false
+	}
+	if (w.Recurring == nil) != (y.Recurring == nil) {
+		return false
+	}
+
+	return w.Recurring == nil || w.Recurring.String() == y.Recurring.String()
+}
+
+func parseTime(str string, loc *time.Location) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		t, err := time.ParseInLocation(layout, str, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time '%s', expected e.g. '2006-01-02 15:04'", str)
+}
+
+func (w *Window) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type alias struct {
+		Name     string `yaml:"name"`
+		Mode     Mode   `yaml:"mode"`
+		From     string `yaml:"from"`
+		To       string `yaml:"to"`
+		Window   string `yaml:"window"`
+		Timezone string `yaml:"timezone"`
+	}
+
+	var tmp alias
+	if err := unmarshal(&tmp); err != nil {
+		return err
+	}
+
+	w.Name = tmp.Name
+	w.Timezone = strings.TrimSpace(tmp.Timezone)
+
+	switch tmp.Mode {
+	case "":
+		w.Mode = SkipChecksMode
+	case SkipChecksMode, MuteNotificationsMode:
+		w.Mode = tmp.Mode
+	default:
+		return fmt.Errorf("unsupported maintenance mode '%s' in '%s'", tmp.Mode, tmp.Name)
+	}
+
+	if w.Timezone != "" {
+		loc, err := time.LoadLocation(w.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone '%s' in maintenance '%s': %v", w.Timezone, tmp.Name, err)
+		}
+		w.location = loc
+	}
+
+	if tmp.Window != "" {
+		if tmp.From != "" || tmp.To != "" {
+			return fmt.Errorf("maintenance '%s' can't have both window and from/to", tmp.Name)
+		}
+		w.Recurring = &scheduler.Window{}
+		return w.Recurring.FromString(tmp.Window)
+	}
+
+	if tmp.From == "" || tmp.To == "" {
+		return fmt.Errorf("maintenance '%s' needs either window or both from and to", tmp.Name)
+	}
+
+	var err error
+	if w.From, err = parseTime(tmp.From, w.Location()); err != nil {
+		return err
+	}
+	if w.To, err = parseTime(tmp.To, w.Location()); err != nil {
+		return err
+	}
+	if !w.To.After(w.From) {
+		return fmt.Errorf("maintenance '%s' ends before it starts", tmp.Name)
+	}
+
+	return nil
+}
+
+type Windows []*Window
+
+// Active returns the window active at t, preferring windows which skip
+// checks over windows which only mute notifications, or nil if none are.
+func (ws Windows) Active(t time.Time) *Window {
+	var active *Window
+	for _, w := range ws {
+		if !w.IsActive(t) {
+			continue
+		}
+		if w.Mode == SkipChecksMode {
+			return w
+		}
+		if active == nil {
+			active = w
+		}
+	}
+
+	return active
+}
+
+func (ws Windows) Equal(y Windows) bool {
+	if len(ws) != len(y) {
+		return false
+	}
+	for i := range ws {
+		if !ws[i].Equal(y[i]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,159 @@
+package maintenance_test
+
+import (
+	"gopkg.in/yaml.v3"
+	"testing"
+	"time"
+	"website-monitor/maintenance"
+)
+
+func TestWindow_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+		err      bool
+	}{
+		{
+			name: "absolute range",
+			data: `
+name: Upgrade
+from: "2021-03-01 22:00"
+to: "2021-03-02 02:00"
+`,
+			expected: "Upgrade (2021-03-01T22:00:00Z - 2021-03-02T02:00:00Z, skip_checks)",
+		},
+		{
+			name: "absolute range in timezone",
+			data: `
+name: Upgrade
+from: "2021-03-01 22:00"
+to: "2021-03-02"
+timezone: Europe/Oslo
+mode: mute_notifications
+`,
+			expected: "Upgrade (2021-03-01T22:00:00+01:00 - 2021-03-02T00:00:00+01:00, mute_notifications)",
+		},
+		{
+			name: "recurring",
+			data: `
+name: Backup
+window: "sun 02:00-04:00"
+mode: mute_notifications
+`,
+			expected: "Backup (0 02:00-04:00, mute_notifications)",
+		},
+		{
+			name: "invalid mode",
+			data: `
+name: Backup
+window: "sun 02:00-04:00"
+mode: snooze
+`,
+			err: true,
+		},
+		{
+			name: "both window and range",
+			data: `
+name: Backup
+window: "sun 02:00-04:00"
+from: "2021-03-01 22:00"
+`,
+			err: true,
+		},
+		{
+			name: "missing to",
+			data: `
+name: Upgrade
+from: "2021-03-01 22:00"
+`,
+			err: true,
+		},
+		{
+			name: "ends before start",
+			data: `
+name: Upgrade
+from: "2021-03-02 22:00"
+to: "2021-03-01 22:00"
+`,
+			err: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := &maintenance.Window{}
+			err := yaml.Unmarshal([]byte(test.data), w)
+			if (err != nil) != test.err {
+				t.Fatalf("got err %v, expected err: %t", err, test.err)
+			}
+			if err == nil && w.String() != test.expected {
+				t.Errorf("got '%s', expected '%s'", w.String(), test.expected)
+			}
+		})
+	}
+}
+
+func TestWindows_Active(t *testing.T) {
+	var ws maintenance.Windows
+	data := `
+- name: Upgrade
+  from: "2021-03-01 22:00"
+  to: "2021-03-02 02:00"
+  mode: mute_notifications
+- name: Backup
+  window: "tue 01:00-03:00"
+  timezone: Europe/Oslo
+  mode: skip_checks
+`
+	if err := yaml.Unmarshal([]byte(data), &ws); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		value    time.Time
+		expected string
+	}{
+		{
+			name:     "none active",
+			value:    time.Date(2021, 03, 01, 21, 59, 00, 00, time.UTC),
+			expected: "",
+		},
+		{
+			name:     "absolute active",
+			value:    time.Date(2021, 03, 01, 22, 00, 00, 00, time.UTC),
+			expected: "Upgrade",
+		},
+		{
+			name:     "both active, skip wins",
+			value:    time.Date(2021, 03, 02, 00, 30, 00, 00, time.UTC),
+			expected: "Backup",
+		},
+		{
+			name:     "recurring active in timezone",
+			value:    time.Date(2021, 03, 9, 01, 30, 00, 00, time.UTC),
+			expected: "Backup",
+		},
+		{
+			name:     "recurring ended in timezone",
+			value:    time.Date(2021, 03, 9, 02, 00, 00, 00, time.UTC),
+			expected: "",
+		},
+		{
+			name:     "absolute end is exclusive",
+			value:    time.Date(2021, 03, 02, 02, 00, 00, 00, time.UTC),
+			expected: "",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ""
+			if w := ws.Active(test.value); w != nil {
+				got = w.Name
+			}
+			if got != test.expected {
+				t.Errorf("got '%s', expected '%s'", got, test.expected)
+			}
+		})
+	}
+}
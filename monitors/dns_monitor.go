@@ -0,0 +1,255 @@
+package monitors
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/result"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DefaultResolver is used when there is no resolver in the config or in
+// /etc/resolv.conf.
+const DefaultResolver = "127.0.0.1:53"
+
+var recordTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"TXT":   dnsmessage.TypeTXT,
+	"NS":    dnsmessage.TypeNS,
+}
+
+// DnsMonitor resolves the records of a type for the monitor's url, which is
+// a domain name, and checks them.
+type DnsMonitor struct{}
+
+func (dm *DnsMonitor) Check(ctx context.Context, check Monitor) (*result.Results, error) {
+	qtype, err := check.recordType()
+	if err != nil {
+		return nil, err
+	}
+	name, err := dnsmessage.NewName(dnsName(check.Url))
+	if err != nil {
+		return nil, fmt.Errorf("invalid domain name '%s': %v", check.Url, err)
+	}
+	resolver := check.Resolver
+	if resolver == "" {
+		resolver = systemResolver()
+	}
+	if _, _, err := net.SplitHostPort(resolver); err != nil {
+		resolver = net.JoinHostPort(resolver, "53")
+	}
+
+	var results *result.Results
+	attempts, err := check.retry(ctx, func(last bool) (bool, error) {
+		var err error
+		results, err = dm.attempt(ctx, check, resolver, name, qtype)
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	results.Attempts = attempts
+
+	return results, nil
+}
+
+// attempt asks the resolver for the records. A name which doesn't exist has
+// no records, other failures to resolve are errors.
+func (dm *DnsMonitor) attempt(ctx context.Context, check Monitor, resolver string, name dnsmessage.Name, qtype dnsmessage.Type) (*result.Results, error) {
+	ctx, cancel := context.WithTimeout(ctx, check.timeout(DefaultHttpTimeout))
+	defer cancel()
+
+	id := uint16(rand.Intn(1 << 16))
+	query, err := newQuery(id, name, qtype)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &net.Dialer{Timeout: check.ConnectTimeout}
+	started := time.Now()
+	answer, err := exchange(ctx, d, "udp", resolver, query)
+	if err != nil {
+		return nil, err
+	}
+	if answer.Truncated {
+		if answer, err = exchange(ctx, d, "tcp", resolver, query); err != nil {
+			return nil, err
+		}
+	}
+	timings := content_checkers.Timings{Total: time.Since(started)}
+
+	if answer.ID != id {
+		return nil, fmt.Errorf("dns answer for another query from %s", resolver)
+	}
+	switch answer.RCode {
+	case dnsmessage.RCodeSuccess, dnsmessage.RCodeNameError:
+	default:
+		return nil, fmt.Errorf("dns query for %s %s failed: %s", name, qtype, answer.RCode)
+	}
+
+	response := &content_checkers.Response{
+		FinalURL: check.Url,
+		Timings:  timings,
+		Records:  records(answer.Answers, qtype),
+	}
+
+	checks := check.ContentChecks
+	if len(checks) == 0 {
+		checks = content_checkers.DefaultDNSChecks()
+	}
+
+	return &result.Results{
+		Results: runChecks(checks, response, nil),
+		Timings: timings,
+	}, nil
+}
+
+func newQuery(id uint16, name dnsmessage.Name, qtype dnsmessage.Type) ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, RecursionDesired: true})
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(dnsmessage.Question{Name: name, Type: qtype, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+
+	return b.Finish()
+}
+
+// exchange sends the query and reads the answer, over tcp with the length
+// prefixed.
+func exchange(ctx context.Context, d *net.Dialer, network, resolver string, query []byte) (*dnsmessage.Message, error) {
+	conn, err := d.DialContext(ctx, network, resolver)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	var answer []byte
+	if network == "tcp" {
+		msg := make([]byte, 2+len(query))
+		binary.BigEndian.PutUint16(msg, uint16(len(query)))
+		copy(msg[2:], query)
+		if _, err := conn.Write(msg); err != nil {
+			return nil, err
+		}
+		var length uint16
+		if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+			return nil, err
+		}
+		answer = make([]byte, length)
+		if _, err := io.ReadFull(conn, answer); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := conn.Write(query); err != nil {
+			return nil, err
+		}
+		answer = make([]byte, 4096)
+		n, err := conn.Read(answer)
+		if err != nil {
+			return nil, err
+		}
+		answer = answer[:n]
+	}
+
+	var m dnsmessage.Message
+	if err := m.Unpack(answer); err != nil {
+		return nil, fmt.Errorf("invalid dns answer from %s: %v", resolver, err)
+	}
+
+	return &m, nil
+}
+
+// records formats the answers of the queried type, sorted. Answers can
+// include the CNAME records leading to them.
+func records(answers []dnsmessage.Resource, qtype dnsmessage.Type) []string {
+	var records []string
+	for _, a := range answers {
+		if a.Header.Type != qtype {
+			continue
+		}
+		switch r := a.Body.(type) {
+		case *dnsmessage.AResource:
+			records = append(records, net.IP(r.A[:]).String())
+		case *dnsmessage.AAAAResource:
+			records = append(records, net.IP(r.AAAA[:]).String())
+		case *dnsmessage.CNAMEResource:
+			records = append(records, r.CNAME.String())
+		case *dnsmessage.NSResource:
+			records = append(records, r.NS.String())
+		case *dnsmessage.MXResource:
+			records = append(records, fmt.Sprintf("%d %s", r.Pref, r.MX))
+		case *dnsmessage.TXTResource:
+			records = append(records, strings.Join(r.TXT, ""))
+		}
+	}
+	sort.Strings(records)
+
+	return records
+}
+
+// recordType returns the type to query for, A by default.
+func (c *Monitor) recordType() (dnsmessage.Type, error) {
+	if c.RecordType == "" {
+		return dnsmessage.TypeA, nil
+	}
+	t, ok := recordTypes[strings.ToUpper(c.RecordType)]
+	if !ok {
+		return 0, fmt.Errorf("unsupported record_type '%s', expected A, AAAA, CNAME, MX, TXT or NS", c.RecordType)
+	}
+
+	return t, nil
+}
+
+// dnsName returns the fully qualified name for a url like "example.com" or
+// "dns://example.com".
+func dnsName(target string) string {
+	name := strings.TrimPrefix(target, "dns://")
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+
+	return name
+}
+
+// systemResolver returns the first nameserver in /etc/resolv.conf.
+func systemResolver() string {
+	f, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return DefaultResolver
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return net.JoinHostPort(fields[1], "53")
+		}
+	}
+
+	return DefaultResolver
+}
+
+func (dm *DnsMonitor) Type() string {
+	return "DnsMonitor"
+}
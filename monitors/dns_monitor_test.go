@@ -0,0 +1,219 @@
+package monitors_test
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"website-monitor/content_checkers"
+	"website-monitor/monitors"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsServer is a stand-in resolver on udp and tcp, answering from zone.
+// Answers over udp for truncated.example are truncated, so they have to be
+// asked for again over tcp.
+type dnsServer struct {
+	udp net.PacketConn
+	tcp net.Listener
+
+	mu   sync.Mutex
+	zone map[dnsmessage.Question][]dnsmessage.Resource
+}
+
+func newDnsServer(t *testing.T) *dnsServer {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+	if err != nil {
+		udp.Close()
+		t.Skipf("tcp port of the udp listener is taken: %v", err)
+	}
+
+	s := &dnsServer{udp: udp, tcp: tcp, zone: make(map[dnsmessage.Question][]dnsmessage.Resource)}
+	go s.serveUDP()
+	go s.serveTCP()
+
+	return s
+}
+
+func (s *dnsServer) Addr() string {
+	return s.udp.LocalAddr().String()
+}
+
+func (s *dnsServer) Close() {
+	s.udp.Close()
+	s.tcp.Close()
+}
+
+func (s *dnsServer) add(name string, qtype dnsmessage.Type, bodies ...dnsmessage.ResourceBody) {
+	q := dnsmessage.Question{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, b := range bodies {
+		s.zone[q] = append(s.zone[q], dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Type: qtype, Class: dnsmessage.ClassINET, TTL: 300},
+			Body:   b,
+		})
+	}
+}
+
+func (s *dnsServer) answer(query []byte, udp bool) []byte {
+	var m dnsmessage.Message
+	if err := m.Unpack(query); err != nil || len(m.Questions) != 1 {
+		return nil
+	}
+	q := m.Questions[0]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m.Response = true
+	answers, ok := s.zone[q]
+	switch {
+	case udp && q.Name.String() == "truncated.example.":
+		m.Truncated = true
+	case ok:
+		m.Answers = answers
+	case q.Name.String() == "servfail.example.":
+		m.RCode = dnsmessage.RCodeServerFailure
+	default:
+		m.RCode = dnsmessage.RCodeNameError
+		for k := range s.zone {
+			if k.Name == q.Name {
+				// the name exists, without records of the type
+				m.RCode = dnsmessage.RCodeSuccess
+			}
+		}
+	}
+
+	answer, _ := m.Pack()
+	return answer
+}
+
+func (s *dnsServer) serveUDP() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		_, _ = s.udp.WriteTo(s.answer(buf[:n], true), addr)
+	}
+}
+
+func (s *dnsServer) serveTCP() {
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			return
+		}
+		var length uint16
+		if err := binary.Read(conn, binary.BigEndian, &length); err == nil {
+			query := make([]byte, length)
+			if _, err := io.ReadFull(conn, query); err == nil {
+				answer := s.answer(query, false)
+				_ = binary.Write(conn, binary.BigEndian, uint16(len(answer)))
+				_, _ = conn.Write(answer)
+			}
+		}
+		conn.Close()
+	}
+}
+
+func dnsChecker(t *testing.T, mode content_checkers.MatchMode, value string) content_checkers.ContentCheckerHolder {
+	c, err := content_checkers.NewDNSRecordsChecker("records", mode, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return content_checkers.ContentCheckerHolder{ContentChecker: c}
+}
+
+func TestDnsMonitor_Check(t *testing.T) {
+	s := newDnsServer(t)
+	defer s.Close()
+	s.add("example.com.", dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}}, &dnsmessage.AResource{A: [4]byte{192, 0, 2, 2}})
+	s.add("example.com.", dnsmessage.TypeAAAA, &dnsmessage.AAAAResource{AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}})
+	s.add("example.com.", dnsmessage.TypeMX, &dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mail.example.com.")})
+	s.add("example.com.", dnsmessage.TypeTXT, &dnsmessage.TXTResource{TXT: []string{"v=spf1 -all"}})
+	s.add("example.com.", dnsmessage.TypeNS, &dnsmessage.NSResource{NS: dnsmessage.MustNewName("ns1.example.net.")})
+	s.add("www.example.com.", dnsmessage.TypeCNAME, &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("example.com.")})
+	s.add("truncated.example.", dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte{192, 0, 2, 3}})
+
+	tests := []struct {
+		name       string
+		url        string
+		recordType string
+		checks     []content_checkers.ContentCheckerHolder
+		expected   []bool
+	}{
+		{"resolves", "example.com", "", nil, []bool{true}},
+		{
+			name: "a records",
+			url:  "example.com",
+			checks: []content_checkers.ContentCheckerHolder{
+				dnsChecker(t, content_checkers.EqualsMatch, "192.0.2.2, 192.0.2.1"),
+				dnsChecker(t, content_checkers.EqualsMatch, "192.0.2.1"),
+				dnsChecker(t, content_checkers.ContainsMatch, "192.0.2.1"),
+				dnsChecker(t, content_checkers.RegexMatch, `^192\.0\.2\.\d+$`),
+			},
+			expected: []bool{true, false, true, true},
+		},
+		{"aaaa", "example.com", "AAAA", []content_checkers.ContentCheckerHolder{dnsChecker(t, "", "2001:db8::1")}, []bool{true}},
+		{"cname", "www.example.com", "cname", []content_checkers.ContentCheckerHolder{dnsChecker(t, "", "Example.com")}, []bool{true}},
+		{"mx", "example.com", "MX", []content_checkers.ContentCheckerHolder{dnsChecker(t, "", "10 mail.example.com")}, []bool{true}},
+		{"txt", "example.com", "TXT", []content_checkers.ContentCheckerHolder{dnsChecker(t, content_checkers.RegexMatch, "^v=spf1 ")}, []bool{true}},
+		{"ns", "example.com", "NS", []content_checkers.ContentCheckerHolder{dnsChecker(t, "", "ns1.example.net.")}, []bool{true}},
+		{"no records of the type", "www.example.com", "A", nil, []bool{false}},
+		{"nxdomain", "missing.example.com", "A", nil, []bool{false}},
+		{"nxdomain not present", "missing.example.com", "A", []content_checkers.ContentCheckerHolder{dnsChecker(t, content_checkers.NotPresentMatch, "")}, []bool{true}},
+		{"truncated over tcp", "truncated.example", "A", []content_checkers.ContentCheckerHolder{dnsChecker(t, "", "192.0.2.3")}, []bool{true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := monitors.Monitor{
+				Name:          "dns",
+				Url:           tt.url,
+				Type:          monitors.DnsMonitorType,
+				Resolver:      s.Addr(),
+				RecordType:    tt.recordType,
+				ContentChecks: tt.checks,
+			}
+			dm := monitors.DnsMonitor{}
+			res, err := dm.Check(context.Background(), ch)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			if len(res.Results) != len(tt.expected) {
+				t.Fatalf("got %d results, expected %d", len(res.Results), len(tt.expected))
+			}
+			for i, r := range res.Results {
+				if r.Result != tt.expected[i] {
+					t.Errorf("%s: got %t (err: %v), expected %t", r.ContentChecker, r.Result, r.Err, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDnsMonitor_CheckServerFailure(t *testing.T) {
+	s := newDnsServer(t)
+	defer s.Close()
+
+	ch := monitors.Monitor{
+		Name:     "dns",
+		Url:      "servfail.example",
+		Type:     monitors.DnsMonitorType,
+		Resolver: s.Addr(),
+	}
+	dm := monitors.DnsMonitor{}
+	if _, err := dm.Check(context.Background(), ch); err == nil {
+		t.Errorf("got nil, expected an error for the server failure")
+	}
+}
@@ -0,0 +1,145 @@
+package monitors
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/result"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GrpcMonitor calls the standard grpc.health.v1.Health service, for the
+// whole server or the monitor's service.
+type GrpcMonitor struct{}
+
+func (gm *GrpcMonitor) Check(ctx context.Context, check Monitor) (*result.Results, error) {
+	addr, err := tcpAddress(check.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := check.grpcCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	var results *result.Results
+	attempts, err := check.retry(ctx, func(last bool) (bool, error) {
+		var err error
+		results, err = gm.attempt(ctx, check, addr, creds)
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	results.Attempts = attempts
+
+	return results, nil
+}
+
+// grpcCredentials are TLS for grpcs:// urls or when the monitor has tls
+// settings, plaintext otherwise.
+func (c *Monitor) grpcCredentials() (grpc.DialOption, error) {
+	if !strings.HasPrefix(c.Url, "grpcs://") && c.TLS == nil {
+		return grpc.WithInsecure(), nil
+	}
+
+	cfg := &TLSConfig{}
+	if c.TLS != nil {
+		cfg = c.TLS
+	}
+	tlsConfig, err := cfg.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
+}
+
+// attempt connects and calls Check. An unknown service is a status like
+// NOT_SERVING, other failed calls are errors.
+func (gm *GrpcMonitor) attempt(ctx context.Context, check Monitor, addr string, creds grpc.DialOption) (*result.Results, error) {
+	ctx, cancel := context.WithTimeout(ctx, check.timeout(DefaultHttpTimeout))
+	defer cancel()
+
+	md, err := check.grpcMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &net.Dialer{Timeout: check.ConnectTimeout}
+	conn, err := grpc.DialContext(ctx, addr, creds, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		return d.DialContext(ctx, "tcp", addr)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	started := time.Now()
+	client := grpc_health_v1.NewHealthClient(conn)
+	resp, err := client.Check(metadata.NewOutgoingContext(ctx, md), &grpc_health_v1.HealthCheckRequest{Service: check.Service})
+	got := resp.GetStatus().String()
+	if err != nil {
+		if status.Code(err) == codes.Unauthenticated && check.Auth != nil {
+			for _, authorization := range md.Get("authorization") {
+				check.Auth.Invalidate(authorization)
+			}
+		}
+		if status.Code(err) != codes.NotFound {
+			return nil, err
+		}
+		got = grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN.String()
+	}
+	timings := content_checkers.Timings{Total: time.Since(started)}
+
+	healthChecker := content_checkers.NewGrpcHealthChecker(check.Service, got)
+	healthy, _ := healthChecker.Check(nil)
+	response := &content_checkers.Response{
+		FinalURL: check.Url,
+		Timings:  timings,
+	}
+	results := &result.Results{
+		Timings: timings,
+		Results: []result.Result{
+			{
+				ContentChecker: healthChecker,
+				Result:         healthy,
+				Required:       true,
+			},
+		},
+	}
+	results.Results = append(results.Results, runChecks(check.ContentChecks, response, nil)...)
+
+	return results, nil
+}
+
+// grpcMetadata sends the monitor's headers, and its auth as authorization.
+func (c *Monitor) grpcMetadata(ctx context.Context) (metadata.MD, error) {
+	md := metadata.New(c.Headers)
+	if c.Auth != nil {
+		hc, err := c.httpClient()
+		if err != nil {
+			return nil, err
+		}
+		authorization, err := c.Auth.Authorization(ctx, hc)
+		if err != nil {
+			return nil, err
+		}
+		md.Set("authorization", authorization)
+	}
+
+	return md, nil
+}
+
+func (gm *GrpcMonitor) Type() string {
+	return "GrpcMonitor"
+}
@@ -0,0 +1,150 @@
+package monitors_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"website-monitor/auth"
+	"website-monitor/monitors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+// healthServer is an in-process grpc server with the health service, which
+// keeps the metadata of the last call.
+type healthServer struct {
+	*health.Server
+	addr string
+
+	mu sync.Mutex
+	md metadata.MD
+}
+
+func newHealthServer(t *testing.T, opts ...grpc.ServerOption) *healthServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hs := &healthServer{Server: health.NewServer(), addr: l.Addr().String()}
+	opts = append(opts, grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		hs.mu.Lock()
+		hs.md, _ = metadata.FromIncomingContext(ctx)
+		hs.mu.Unlock()
+		return handler(ctx, req)
+	}))
+	s := grpc.NewServer(opts...)
+	grpc_health_v1.RegisterHealthServer(s, hs)
+	go func() { _ = s.Serve(l) }()
+	t.Cleanup(s.Stop)
+
+	return hs
+}
+
+func TestGrpcMonitor_Check(t *testing.T) {
+	hs := newHealthServer(t)
+	hs.SetServingStatus("orders", grpc_health_v1.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("payments", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	tests := []struct {
+		name     string
+		service  string
+		expected bool
+	}{
+		{"server", "", true},
+		{"serving", "orders", true},
+		{"not serving", "payments", false},
+		{"unknown service", "shipping", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := monitors.Monitor{
+				Name:    "grpc",
+				Url:     "grpc://" + hs.addr,
+				Type:    monitors.GrpcMonitorType,
+				Service: tt.service,
+			}
+			gm := monitors.GrpcMonitor{}
+			res, err := gm.Check(context.Background(), ch)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+			if len(res.Results) != 1 || !res.Results[0].Required {
+				t.Fatalf("got %v, expected a required health result", res.Results)
+			}
+			if res.Results[0].Result != tt.expected {
+				t.Errorf("%s: got %t, expected %t", res.Results[0].ContentChecker, res.Results[0].Result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGrpcMonitor_CheckMetadata(t *testing.T) {
+	hs := newHealthServer(t)
+
+	ch := monitors.Monitor{
+		Name:    "grpc",
+		Url:     hs.addr,
+		Type:    monitors.GrpcMonitorType,
+		Headers: map[string]string{"X-Tenant": "monitoring"},
+		Auth:    &auth.Config{Type: auth.BearerType, Token: "token"},
+	}
+	gm := monitors.GrpcMonitor{}
+	if _, err := gm.Check(context.Background(), ch); err != nil {
+		t.Fatalf("got err %v, expected nil", err)
+	}
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if got := hs.md.Get("x-tenant"); len(got) != 1 || got[0] != "monitoring" {
+		t.Errorf("got x-tenant %v, expected [monitoring]", got)
+	}
+	if got := hs.md.Get("authorization"); len(got) != 1 || got[0] != "Bearer token" {
+		t.Errorf("got authorization %v, expected [Bearer token]", got)
+	}
+}
+
+func TestGrpcMonitor_CheckTLS(t *testing.T) {
+	// The test server's certificate, valid for 127.0.0.1.
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	cert := ts.TLS.Certificates[0]
+	hs := newHealthServer(t, grpc.Creds(credentials.NewServerTLSFromCert(&cert)))
+
+	tests := []struct {
+		name string
+		url  string
+		tls  *monitors.TLSConfig
+		err  bool
+	}{
+		{"ca file", "grpcs://" + hs.addr, &monitors.TLSConfig{CAFile: writeCA(t, ts)}, false},
+		{"insecure skip verify", hs.addr, &monitors.TLSConfig{InsecureSkipVerify: true}, false},
+		{"untrusted", "grpcs://" + hs.addr, nil, true},
+		{"plaintext", "grpc://" + hs.addr, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := monitors.Monitor{
+				Name: "grpc",
+				Url:  tt.url,
+				Type: monitors.GrpcMonitorType,
+				TLS:  tt.tls,
+			}
+			gm := monitors.GrpcMonitor{}
+			res, err := gm.Check(context.Background(), ch)
+			if (err != nil) != tt.err {
+				t.Fatalf("got err %v, expected err %t", err, tt.err)
+			}
+			if err == nil && !res.Results[0].Result {
+				t.Errorf("got %s, expected serving", res.Results[0].ContentChecker)
+			}
+		})
+	}
+}
@@ -2,52 +2,167 @@ package monitors
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptrace"
 	"time"
+	"website-monitor/content_checkers"
 	"website-monitor/result"
 )
 
 type HttpMonitor struct{}
 
-func (jm *HttpMonitor) Check(check Monitor) (*result.Results, error) {
-	req, err := http.NewRequest(http.MethodGet, check.Url, nil)
+func (jm *HttpMonitor) Check(ctx context.Context, check Monitor) (*result.Results, error) {
+	hc, err := check.httpClient()
 	if err != nil {
 		return nil, err
 	}
-	for k, v := range check.Headers {
-		req.Header.Add(k, v)
+
+	var results *result.Results
+	attempts, err := check.retry(ctx, func(last bool) (bool, error) {
+		var retryable bool
+		var err error
+		results, retryable, err = jm.attempt(ctx, hc, check, last)
+		return retryable, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	results.Attempts = attempts
+
+	return results, nil
+}
+
+// attempt makes one request. A status code which is retried is only checked
+// on the last attempt.
+func (jm *HttpMonitor) attempt(ctx context.Context, hc *http.Client, check Monitor, last bool) (*result.Results, bool, error) {
+	req, err := check.NewRequest(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var authorization string
+	if check.Auth != nil {
+		// Getting an oauth2 token can fail like the request itself.
+		authorization, err = check.Auth.Authorization(ctx, hc)
+		if err != nil {
+			return nil, true, err
+		}
+		req.Header.Set("Authorization", authorization)
 	}
 
-	hc := http.Client{}
-	hc.Timeout = 5 * time.Second
+	trace := newTimingTrace()
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.ClientTrace()))
+	started := time.Now()
+
 	resp, err := hc.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, true, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized && authorization != "" {
+		check.Auth.Invalidate(authorization)
+	}
 
-	if resp.StatusCode != check.ExpectedStatusCode {
-		return nil, fmt.Errorf("invalid statuscode: %d", resp.StatusCode)
+	if !last && check.shouldRetryStatus(resp.StatusCode) {
+		return nil, true, fmt.Errorf("retryable statuscode: %d", resp.StatusCode)
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, true, err
 	}
+	timings := trace.Timings()
+	timings.Total = time.Since(started)
 
-	results := &result.Results{}
-	for _, contentCheck := range check.ContentChecks {
-		res, err := contentCheck.ContentChecker.Check(ioutil.NopCloser(bytes.NewBuffer(body)))
-		results.Results = append(results.Results, result.Result{
+	statusChecker := content_checkers.NewStatusCodeChecker(check.ExpectedStatusCode, resp.StatusCode)
+	statusOk, _ := statusChecker.Check(nil)
+	response := newResponse(resp)
+	response.Timings = timings
+	if check.TLS != nil && check.TLS.ServerName != "" {
+		response.ServerName = check.TLS.ServerName
+	}
+	if t, ok := hc.Transport.(*http.Transport); ok && t.TLSClientConfig != nil {
+		response.Roots = t.TLSClientConfig.RootCAs
+	}
+	results := &result.Results{
+		Timings:           timings,
+		CertificateExpiry: content_checkers.CertificateExpiry(resp.TLS),
+		Results: []result.Result{
+			{
+				ContentChecker: statusChecker,
+				Result:         statusOk,
+				Required:       true,
+			},
+		},
+	}
+	results.Results = append(results.Results, runChecks(check.ContentChecks, response, body)...)
+
+	return results, false, nil
+}
+
+// runChecks checks the response with checkers which implement
+// content_checkers.ResponseChecker and the body with the others.
+func runChecks(checks []content_checkers.ContentCheckerHolder, response *content_checkers.Response, body []byte) []result.Result {
+	var results []result.Result
+	for _, contentCheck := range checks {
+		var res bool
+		var err error
+		if rc, ok := contentCheck.ContentChecker.(content_checkers.ResponseChecker); ok {
+			res, err = rc.CheckResponse(response)
+		} else {
+			res, err = contentCheck.ContentChecker.Check(ioutil.NopCloser(bytes.NewBuffer(body)))
+		}
+		results = append(results, result.Result{
 			ContentChecker: contentCheck.ContentChecker,
-			Result: res,
-			Err: err,
+			Result:         res,
+			Err:            err,
 		})
 	}
 
-	return results, nil
+	return results
+}
+
+// newResponse collects what checkers see of resp besides its body.
+func newResponse(resp *http.Response) *content_checkers.Response {
+	r := &content_checkers.Response{
+		StatusCode: resp.StatusCode,
+		FinalURL:   resp.Request.URL.String(),
+		Header:     resp.Header,
+		Cookies:    resp.Cookies(),
+		TLS:        resp.TLS,
+		ServerName: resp.Request.URL.Hostname(),
+	}
+
+	for req := resp.Request; req.Response != nil; req = req.Response.Request {
+		r.Redirects = append([]content_checkers.Redirect{newRedirect(req.Response)}, r.Redirects...)
+	}
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 && resp.Header.Get("Location") != "" {
+		r.Redirects = append(r.Redirects, newRedirect(resp))
+	}
+
+	return r
+}
+
+// httpClient returns a client with the monitor's timeouts and the shared
+// transport for its connection settings. The timeout covers each attempt,
+// not all retries together.
+func (c *Monitor) httpClient() (*http.Client, error) {
+	hc := &http.Client{
+		Timeout:       c.timeout(DefaultHttpTimeout),
+		CheckRedirect: c.checkRedirect,
+	}
+
+	t, err := c.transport()
+	if err != nil {
+		return nil, err
+	}
+	if t != nil {
+		hc.Transport = t
+	}
+
+	return hc, nil
 }
 
 func (jm *HttpMonitor) Type() string {
@@ -1,10 +1,13 @@
 package monitors_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+	"website-monitor/auth"
 	"website-monitor/content_checkers"
 	"website-monitor/monitors"
 )
@@ -83,11 +86,11 @@ func TestHttpMonitor_Check(t *testing.T) {
 			ch := monitors.Monitor{
 				Name:               test.name,
 				Url:                ts.URL,
-				ExpectedStatusCode: 200,
+				ExpectedStatusCode: content_checkers.NewStatusCodes(200),
 				ContentChecks:      test.checkers,
 			}
 			hm := monitors.HttpMonitor{}
-			res, err := hm.Check(ch)
+			res, err := hm.Check(context.Background(), ch)
 			if err != test.err {
 				t.Errorf("got err: %v, expected nil", test.err)
 			}
@@ -106,3 +109,206 @@ func TestHttpMonitor_Check(t *testing.T) {
 		})
 	}
 }
+
+func TestHttpMonitor_CheckStatusCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		expected    content_checkers.StatusCodes
+		requireSome bool
+		result      bool
+	}{
+		{name: "single code", status: 200, expected: content_checkers.NewStatusCodes(200), result: true},
+		{name: "one of several", status: 203, expected: content_checkers.NewStatusCodes(200, 203), result: true},
+		{name: "class", status: 202, expected: content_checkers.StatusCodes{{Min: 200, Max: 299}}, result: true},
+		{name: "unexpected", status: 500, expected: content_checkers.NewStatusCodes(200, 304), result: false},
+		{name: "unexpected with require_some", status: 500, expected: content_checkers.NewStatusCodes(200), requireSome: true, result: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(test.status)
+					_, _ = fmt.Fprintln(w, "Some sort of text test.")
+				}))
+			defer ts.Close()
+
+			ch := monitors.Monitor{
+				Name:               test.name,
+				Url:                ts.URL,
+				ExpectedStatusCode: test.expected,
+				ContentChecks: []content_checkers.ContentCheckerHolder{
+					{ContentChecker: content_checkers.NewRegexChecker("regex", "sort of text", true)},
+				},
+			}
+			hm := monitors.HttpMonitor{}
+			res, err := hm.Check(context.Background(), ch)
+			if err != nil {
+				t.Fatalf("got err %v, expected the status code as a result", err)
+			}
+
+			if len(res.Results) != 2 {
+				t.Fatalf("got %d results, expected the status code and the regex", len(res.Results))
+			}
+			got := res.AllTrue()
+			if test.requireSome {
+				got = res.SomeTrue()
+			}
+			if got != test.result {
+				t.Errorf("got %t, expected %t", got, test.result)
+			}
+		})
+	}
+}
+
+func TestHttpMonitor_CheckHeadersAndCookies(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Stock-Level", "0")
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123"})
+			_, _ = fmt.Fprintln(w, "Some sort of text test.")
+		}))
+	defer ts.Close()
+
+	header, _ := content_checkers.NewHeaderChecker("in stock", "X-Stock-Level", content_checkers.RegexMatch, "^[1-9]")
+	cookie, _ := content_checkers.NewCookieChecker("session", "session", content_checkers.ExistsMatch, "")
+	ch := monitors.Monitor{
+		Name:               "headers",
+		Url:                ts.URL,
+		ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+		ContentChecks: []content_checkers.ContentCheckerHolder{
+			{ContentChecker: header},
+			{ContentChecker: cookie},
+		},
+	}
+	hm := monitors.HttpMonitor{}
+	res, err := hm.Check(context.Background(), ch)
+	if err != nil {
+		t.Fatalf("got err %v, expected nil", err)
+	}
+
+	expected := []bool{true, false, true}
+	for i, r := range res.Results {
+		if r.Result != expected[i] {
+			t.Errorf("%s: got %t (err: %v), expected %t", r.ContentChecker, r.Result, r.Err, expected[i])
+		}
+	}
+}
+
+func TestHttpMonitor_CheckResponseTime(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(50 * time.Millisecond)
+			_, _ = fmt.Fprintln(w, "Some sort of text test.")
+		}))
+	defer ts.Close()
+
+	fast, _ := content_checkers.NewResponseTimeChecker("fast", "ttfb", "10ms")
+	slow, _ := content_checkers.NewResponseTimeChecker("slow", "total", "5s")
+	ch := monitors.Monitor{
+		Name:               "timing",
+		Url:                ts.URL,
+		ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+		ContentChecks: []content_checkers.ContentCheckerHolder{
+			{ContentChecker: fast},
+			{ContentChecker: slow},
+		},
+	}
+	hm := monitors.HttpMonitor{}
+	res, err := hm.Check(context.Background(), ch)
+	if err != nil {
+		t.Fatalf("got err %v, expected nil", err)
+	}
+
+	if res.Timings.TTFB < 50*time.Millisecond || res.Timings.Total < res.Timings.TTFB {
+		t.Errorf("got timings %+v, expected ttfb of at least 50ms within total", res.Timings)
+	}
+	if res.Timings.Connect <= 0 {
+		t.Errorf("got timings %+v, expected the connect to be timed", res.Timings)
+	}
+	if res.Results[1].Result || !res.Results[2].Result {
+		t.Errorf("got %t and %t, expected only the 10ms threshold to fail", res.Results[1].Result, res.Results[2].Result)
+	}
+}
+
+func TestHttpMonitor_CheckAuth(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		auth     *auth.Config
+		expected bool
+	}{
+		{"without auth", nil, false},
+		{"basic", &auth.Config{Type: auth.BasicType, Username: "user", Password: "pass"}, true},
+		{"wrong password", &auth.Config{Type: auth.BasicType, Username: "user", Password: "wrong"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := monitors.Monitor{
+				Name:               "auth",
+				Url:                ts.URL,
+				ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+				Auth:               tt.auth,
+			}
+			hm := monitors.HttpMonitor{}
+			res, err := hm.Check(context.Background(), ch)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+			if res.Results[0].Result != tt.expected {
+				t.Errorf("got status check %t, expected %t", res.Results[0].Result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHttpMonitor_CheckAuthRejectedToken(t *testing.T) {
+	var issued int
+	tokens := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			issued++
+			fmt.Fprintf(w, `{"access_token": "token-%d", "expires_in": 3600}`, issued)
+		}))
+	defer tokens.Close()
+
+	// The first token is revoked before it expires.
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token-2" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+	defer ts.Close()
+
+	ch := monitors.Monitor{
+		Name:               "auth",
+		Url:                ts.URL,
+		ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+		Retries:            1,
+		RetryStatusCodes:   []int{http.StatusUnauthorized},
+		Auth: &auth.Config{
+			Type:         auth.OAuth2Type,
+			TokenURL:     tokens.URL,
+			ClientID:     "id",
+			ClientSecret: "secret",
+		},
+	}
+	hm := monitors.HttpMonitor{}
+	res, err := hm.Check(context.Background(), ch)
+	if err != nil {
+		t.Fatalf("got err %v, expected nil", err)
+	}
+	if !res.Results[0].Result || res.Attempts != 2 {
+		t.Errorf("got status check %t after %d attempts, expected the retry to pass with a new token", res.Results[0].Result, res.Attempts)
+	}
+	if issued != 2 {
+		t.Errorf("got %d tokens issued, expected 2", issued)
+	}
+}
@@ -1,10 +1,13 @@
 package monitors
 
 import (
+	"context"
 	"fmt"
 	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
+	"strings"
 	"time"
+	"website-monitor/content_checkers"
 	"website-monitor/result"
 
 	"github.com/go-rod/rod"
@@ -20,30 +23,69 @@ func NewHttpRenderMonitor(renderServer string) *HttpRenderMonitor {
 	}
 }
 
-func (jm *HttpRenderMonitor) Check(check Monitor) (*result.Results, error) {
+func (jm *HttpRenderMonitor) Check(ctx context.Context, check Monitor) (*result.Results, error) {
+	var results *result.Results
+	attempts, err := check.retry(ctx, func(last bool) (bool, error) {
+		var err error
+		results, err = jm.attempt(ctx, check)
+		// Any error rendering the page is a transport error, there are no
+		// status codes to go by.
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	results.Attempts = attempts
+
+	return results, nil
+}
+
+// attempt renders the page, all within the monitor's timeout. A connect
+// timeout limits connecting to the render server.
+func (jm *HttpRenderMonitor) attempt(ctx context.Context, check Monitor) (*result.Results, error) {
 	l, err := launcher.NewRemote(jm.renderServer)
 	if err != nil {
 		return nil, fmt.Errorf("error connecting to rod at %s: %s", jm.renderServer, err)
 	}
 	l.Set("window-size", "1920,1080")
 
-	r := rod.New().Client(l.Client()).Timeout(10 * time.Second)
-	if err := r.Connect(); err != nil {
-		return nil, err
+	ctx, cancel := context.WithTimeout(ctx, check.timeout(DefaultRenderTimeout))
+	defer cancel()
+
+	r := rod.New().Client(l.Client()).Context(ctx)
+	if err := connect(r, check.ConnectTimeout, cancel); err != nil {
+		return nil, fmt.Errorf("error connecting to rod at %s: %s", jm.renderServer, err)
 	}
 
-	p, err := r.Page(proto.TargetCreateTarget{URL: check.Url})
+	var authorization string
+	if check.Auth != nil {
+		if authorization, err = jm.authorization(ctx, check); err != nil {
+			return nil, err
+		}
+	}
+
+	started := time.Now()
+	p, stop, err := page(r, check.Url, authorization)
 	if err != nil {
 		return nil, err
 	}
+	defer stop()
 
-	if err = p.Timeout(3 * time.Second).WaitLoad(); err != nil {
+	if err = p.WaitLoad(); err != nil {
 		return nil, err
 	}
 
-	results := &result.Results{}
+	results := &result.Results{
+		Timings: content_checkers.Timings{Total: time.Since(started)},
+	}
 	for _, contentCheck := range check.ContentChecks {
-		res, err := contentCheck.ContentChecker.CheckRender(p)
+		var res bool
+		var err error
+		if tc, ok := contentCheck.ContentChecker.(content_checkers.RenderTimeChecker); ok {
+			res, err = tc.CheckRenderTime(results.Timings.Total)
+		} else {
+			res, err = contentCheck.ContentChecker.CheckRender(p)
+		}
 		results.Results = append(results.Results, result.Result{
 			ContentChecker: contentCheck.ContentChecker,
 			Result:         res,
@@ -54,10 +96,90 @@ func (jm *HttpRenderMonitor) Check(check Monitor) (*result.Results, error) {
 	return results, nil
 }
 
+// authorization gets the Authorization header with the monitor's transport
+// settings, oauth2 tokens are requested by the monitor, not the browser.
+func (jm *HttpRenderMonitor) authorization(ctx context.Context, check Monitor) (string, error) {
+	hc, err := check.httpClient()
+	if err != nil {
+		return "", err
+	}
+
+	return check.Auth.Authorization(ctx, hc)
+}
+
+// page opens url. The Authorization header, if there is one, is only added
+// to requests to the monitor's own origin, so it isn't leaked to other hosts
+// the page loads resources from. The returned stop function must be called
+// once the page is no longer used.
+func page(r *rod.Browser, url, authorization string) (*rod.Page, func(), error) {
+	if authorization == "" {
+		p, err := r.Page(proto.TargetCreateTarget{URL: url})
+		return p, func() {}, err
+	}
+
+	p, err := r.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	own := origin(url)
+	router := p.HijackRequests()
+	err = router.Add("*", "", func(h *rod.Hijack) {
+		continued := &proto.FetchContinueRequest{}
+		if u := h.Request.URL(); u != nil && origin(u.String()) == own {
+			continued.Headers = withAuthorization(h.Request.Headers(), authorization)
+		}
+		h.ContinueRequest(continued)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	go router.Run()
+	stop := func() { _ = router.Stop() }
+
+	if err := p.Navigate(url); err != nil {
+		stop()
+		return nil, nil, err
+	}
+
+	return p, stop, nil
+}
+
+// withAuthorization returns headers with the Authorization header set to
+// authorization, replacing one the browser might have set.
+func withAuthorization(headers proto.NetworkHeaders, authorization string) []*proto.FetchHeaderEntry {
+	entries := []*proto.FetchHeaderEntry{{Name: "Authorization", Value: authorization}}
+	for name, value := range headers {
+		if strings.EqualFold(name, "Authorization") {
+			continue
+		}
+		entries = append(entries, &proto.FetchHeaderEntry{Name: name, Value: value.String()})
+	}
+
+	return entries
+}
+
+// connect connects to the browser, calling cancel if it takes longer than
+// timeout. The browser keeps the context it connected with, so the timeout
+// can't be a context of its own.
+func connect(r *rod.Browser, timeout time.Duration, cancel context.CancelFunc) error {
+	if timeout <= 0 {
+		return r.Connect()
+	}
+
+	t := time.AfterFunc(timeout, cancel)
+	err := r.Connect()
+	if !t.Stop() {
+		return fmt.Errorf("connect timeout of %s exceeded", timeout)
+	}
+
+	return err
+}
+
 func (jm *HttpRenderMonitor) Type() string {
 	return "HttpRenderMonitor"
 }
 
 func (jm *HttpRenderMonitor) Equal(y *HttpRenderMonitor) bool {
 	return jm.renderServer == y.renderServer
-}
\ No newline at end of file
+}
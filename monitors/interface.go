@@ -1,8 +1,11 @@
 package monitors
 
-import "website-monitor/result"
+import (
+	"context"
+	"website-monitor/result"
+)
 
 type MonitorInterface interface {
-	Check(check Monitor) (*result.Results, error)
+	Check(ctx context.Context, check Monitor) (*result.Results, error)
 	Type() string
 }
@@ -1,11 +1,19 @@
 package monitors
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/url"
+	"reflect"
 	"time"
+	"website-monitor/auth"
 	"website-monitor/content_checkers"
+	"website-monitor/maintenance"
 	"website-monitor/notifiers"
+	"website-monitor/result"
 	"website-monitor/scheduler"
+	"website-monitor/state"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -13,30 +21,96 @@ import (
 type MonitorType string
 
 const (
-	HttpMonitorType       MonitorType = "http"
-	HttpRenderMonitorType MonitorType = "http_render"
+	HttpMonitorType         MonitorType = "http"
+	HttpRenderMonitorType   MonitorType = "http_render"
+	TlsMonitorType          MonitorType = "tls"
+	TransactionMonitorType  MonitorType = "transaction"
+	TcpMonitorType          MonitorType = "tcp"
+	DnsMonitorType          MonitorType = "dns"
+	ReachabilityMonitorType MonitorType = "reachability"
+	GrpcMonitorType         MonitorType = "grpc"
+	WebSocketMonitorType    MonitorType = "websocket"
+)
+
+type StartupPolicy string
+
+const (
+	// ImmediateStartup checks all monitors right away.
+	ImmediateStartup StartupPolicy = "immediate"
+	// StaggeredStartup spreads the first checks out over each monitor's
+	// interval.
+	StaggeredStartup StartupPolicy = "staggered"
+	// ResumeStartup continues from the state saved before the restart, and
+	// staggers monitors which are overdue or have no saved state.
+	ResumeStartup StartupPolicy = "resume"
 )
 
 type Monitor struct {
 	tableName struct{} `pg:"checks,alias:check"`
 
 	// MonitorInterface
-	ID                 int               `yaml:"-"`
-	Name               string            `yaml:"name"`
-	Url                string            `yaml:"url"`
-	DisplayUrl         string            `yaml:"display_url"`
-	Type               MonitorType       `yaml:"type"`
-	Headers            map[string]string `yaml:"headers" pg:"-"`
-	ExpectedStatusCode int               `yaml:"expected_status_code"`
+	ID                 int                          `yaml:"-"`
+	Name               string                       `yaml:"name"`
+	Url                string                       `yaml:"url"`
+	DisplayUrl         string                       `yaml:"display_url"`
+	Type               MonitorType                  `yaml:"type"`
+	Headers            map[string]string            `yaml:"headers" pg:"-"`
+	ExpectedStatusCode content_checkers.StatusCodes `yaml:"expected_status_code"`
+
+	// Request
+	Method   string            `yaml:"method" pg:"-"`
+	Body     string            `yaml:"body" pg:"-"`
+	BodyFile string            `yaml:"body_file" pg:"-"`
+	Form     map[string]string `yaml:"form" pg:"-"`
+	JsonBody interface{}       `yaml:"json_body" pg:"-"`
+
+	// Timeouts and retries
+	Timeout          time.Duration  `yaml:"timeout" pg:"-"`
+	ConnectTimeout   time.Duration  `yaml:"connect_timeout" pg:"-"`
+	Retries          int            `yaml:"retries" pg:"-"`
+	RetryBackoff     time.Duration  `yaml:"retry_backoff" pg:"-"`
+	RetryStatusCodes []int          `yaml:"retry_status_codes" pg:"-"`
+	FollowRedirects  *RedirectLimit `yaml:"follow_redirects" pg:"-"`
+
+	// Connection
+	TLS   *TLSConfig `yaml:"tls" pg:"-"`
+	Proxy string     `yaml:"proxy" pg:"-"`
+
+	Auth *auth.Config `yaml:"auth" pg:"-"`
+
+	// Steps of a transaction monitor
+	Steps []Step `yaml:"steps" pg:"-"`
+
+	// DNS
+	Resolver   string `yaml:"resolver" pg:"-"`
+	RecordType string `yaml:"record_type" pg:"-"`
+
+	// Reachability
+	ProbeCount    int           `yaml:"probes" pg:"-"`
+	ProbeInterval time.Duration `yaml:"probe_interval" pg:"-"`
+
+	// Service is the service of a grpc health check, the whole server if
+	// empty.
+	Service string `yaml:"service" pg:"-"`
+
+	// WebSocket
+	Messages     []string      `yaml:"messages" pg:"-"`
+	MaxMessages  int           `yaml:"max_messages" pg:"-"`
+	CollectFor   time.Duration `yaml:"collect_for" pg:"-"`
+	MessageMatch MessageMatch  `yaml:"message_match" pg:"-"`
 
 	// Schedule
-	Scheduler *scheduler.Scheduler `yaml:"schedule" pg:"-"`
+	Scheduler   *scheduler.Scheduler `yaml:"schedule" pg:"-"`
+	Maintenance maintenance.Windows  `yaml:"maintenance" pg:"-"`
 
 	// Status
-	lastCheckedAt time.Time `pg:"-" yaml:"-"`
-	nextCheckAt   time.Time `pg:"-" yaml:"-"`
-	CheckPending  bool      `pg:"-" yaml:"-"`
-	LastSeenState bool      `pg:"-" yaml:"-"`
+	lastCheckedAt     time.Time       `pg:"-" yaml:"-"`
+	nextCheckAt       time.Time       `pg:"-" yaml:"-"`
+	burstUntil        time.Time       `pg:"-" yaml:"-"`
+	consecutiveErrors int             `pg:"-" yaml:"-"`
+	lastResults       *result.Results `pg:"-" yaml:"-"`
+	CheckPending      bool            `pg:"-" yaml:"-"`
+	LastSeenState     bool            `pg:"-" yaml:"-"`
 
 	// Config
 	RenderServerURN string                                  `yaml:"render_server_urn" pg:"-"`
@@ -48,24 +122,178 @@ type Monitor struct {
 }
 
 func (c *Monitor) updateTimestamps() {
-	c.lastCheckedAt = time.Now().UTC()
-	c.nextCheckAt = c.Scheduler.CalculateNextFrom(c.lastCheckedAt)
+	c.lastCheckedAt = time.Now()
+	switch {
+	case c.consecutiveErrors > 0:
+		c.nextCheckAt = c.Scheduler.CalculateNextBackoffFrom(c.lastCheckedAt, c.consecutiveErrors)
+	case c.lastCheckedAt.Before(c.burstUntil):
+		c.nextCheckAt = c.Scheduler.CalculateNextBurstFrom(c.lastCheckedAt)
+	default:
+		c.nextCheckAt = c.Scheduler.CalculateNextStaggeredFrom(c.lastCheckedAt, c.Name)
+	}
 	log.Debugf("%s next run: %s (in %ds)", c.Name, c.nextCheckAt.String(), int(c.nextCheckAt.Sub(time.Now()).Seconds()))
 
 	c.CheckPending = false
 }
 
+// Origin returns the scheme, host and port the monitor connects to, which
+// is what per host limits apply to.
+func (c *Monitor) Origin() string {
+	return origin(c.Url)
+}
+
+// origin returns the scheme, host and port of rawurl, with the default port
+// of http and https made explicit.
+func origin(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Host == "" {
+		return rawurl
+	}
+
+	port := u.Port()
+	switch {
+	case port != "":
+	case u.Scheme == "https":
+		port = "443"
+	case u.Scheme == "http":
+		port = "80"
+	default:
+		return fmt.Sprintf("%s://%s", u.Scheme, u.Hostname())
+	}
+
+	return fmt.Sprintf("%s://%s", u.Scheme, net.JoinHostPort(u.Hostname(), port))
+}
+
 func (c *Monitor) NextCheckAt() time.Time {
 	return c.nextCheckAt
 }
 
-func (c *Monitor) ShouldUpdate() bool {
-	// This is probably most correct, but leads to no immediate checks. Should
-	// we do immediate checks?
-	//if c.nextCheckAt.IsZero() {
-	//	c.updateTimestamps()
-	//}
+// Start sets when the monitor is first checked according to policy. saved is
+// the state from before a restart, or nil if there is none.
+func (c *Monitor) Start(policy StartupPolicy, now time.Time, saved *state.MonitorState) {
+	switch policy {
+	case ResumeStartup:
+		if saved != nil {
+			c.Restore(*saved)
+			if c.nextCheckAt.After(now) {
+				return
+			}
+		}
+		c.stagger(now)
+	case StaggeredStartup:
+		c.stagger(now)
+	default:
+		c.nextCheckAt = now
+	}
+}
 
+// stagger sets the next check to a time within the monitor's interval from
+// now, derived from the monitor's name so restarts don't reshuffle monitors.
+func (c *Monitor) stagger(now time.Time) {
+	c.nextCheckAt = now
+	if c.Scheduler == nil {
+		return
+	}
+
+	if c.Scheduler.Stagger {
+		c.nextCheckAt = c.Scheduler.CalculateNextStaggeredFrom(now, c.Name)
+		return
+	}
+
+	span := c.Scheduler.CalculateNextFrom(now).Sub(now)
+	c.nextCheckAt = now.Add(scheduler.StaggerOffset(c.Name, span))
+}
+
+// State returns the part of the monitor's status which is kept across
+// restarts.
+func (c *Monitor) State() state.MonitorState {
+	return state.MonitorState{
+		LastCheckedAt: c.lastCheckedAt,
+		NextCheckAt:   c.nextCheckAt,
+		LastSeenState: c.LastSeenState,
+	}
+}
+
+func (c *Monitor) Restore(st state.MonitorState) {
+	c.lastCheckedAt = st.LastCheckedAt
+	c.nextCheckAt = st.NextCheckAt
+	c.LastSeenState = st.LastSeenState
+}
+
+// Equal reports whether both monitors have the same config, ignoring their
+// status.
+func (c *Monitor) Equal(y *Monitor) bool {
+	if c == nil || y == nil {
+		return c == y
+	}
+	if c.Name != y.Name || c.Url != y.Url || c.DisplayUrl != y.DisplayUrl || c.Type != y.Type {
+		return false
+	}
+	if c.RenderServerURN != y.RenderServerURN || c.RequireSome != y.RequireSome {
+		return false
+	}
+	if !reflect.DeepEqual(c.ExpectedStatusCode, y.ExpectedStatusCode) || !reflect.DeepEqual(c.Headers, y.Headers) {
+		return false
+	}
+	if c.Method != y.Method || c.Body != y.Body || c.BodyFile != y.BodyFile {
+		return false
+	}
+	if !reflect.DeepEqual(c.Form, y.Form) || !reflect.DeepEqual(c.JsonBody, y.JsonBody) {
+		return false
+	}
+	if c.Timeout != y.Timeout || c.ConnectTimeout != y.ConnectTimeout || c.Retries != y.Retries || c.RetryBackoff != y.RetryBackoff {
+		return false
+	}
+	if !reflect.DeepEqual(c.RetryStatusCodes, y.RetryStatusCodes) || !reflect.DeepEqual(c.FollowRedirects, y.FollowRedirects) {
+		return false
+	}
+	if !reflect.DeepEqual(c.TLS, y.TLS) || c.Proxy != y.Proxy || !reflect.DeepEqual(c.Auth, y.Auth) {
+		return false
+	}
+	if c.Resolver != y.Resolver || c.RecordType != y.RecordType || c.ProbeCount != y.ProbeCount || c.ProbeInterval != y.ProbeInterval {
+		return false
+	}
+	if c.Service != y.Service {
+		return false
+	}
+	if !reflect.DeepEqual(c.Messages, y.Messages) || c.MaxMessages != y.MaxMessages || c.CollectFor != y.CollectFor || c.MessageMatch != y.MessageMatch {
+		return false
+	}
+	if !c.Scheduler.Equal(y.Scheduler) || !c.Maintenance.Equal(y.Maintenance) {
+		return false
+	}
+
+	if len(c.ContentChecks) != len(y.ContentChecks) {
+		return false
+	}
+	for i := range c.ContentChecks {
+		if !c.ContentChecks[i].Equal(y.ContentChecks[i]) {
+			return false
+		}
+	}
+
+	if len(c.Steps) != len(y.Steps) {
+		return false
+	}
+	for i := range c.Steps {
+		if !c.Steps[i].Equal(y.Steps[i]) {
+			return false
+		}
+	}
+
+	if len(c.Notifiers) != len(y.Notifiers) {
+		return false
+	}
+	for i := range c.Notifiers {
+		if !c.Notifiers[i].Equal(y.Notifiers[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *Monitor) ShouldUpdate() bool {
 	if !c.CheckPending && c.nextCheckAt.Sub(time.Now()) <= 0 {
 		return true
 	}
@@ -73,8 +301,27 @@ func (c *Monitor) ShouldUpdate() bool {
 	return false
 }
 
-func (c *Monitor) Run() error {
+// ActiveMaintenance returns the maintenance window the monitor is in at t,
+// or nil if it isn't in maintenance.
+func (c *Monitor) ActiveMaintenance(t time.Time) *maintenance.Window {
+	return c.Maintenance.Active(t)
+}
+
+// LastResults returns the results of the last check, or nil if it didn't
+// get any because it was skipped or failed.
+func (c *Monitor) LastResults() *result.Results {
+	return c.lastResults
+}
+
+func (c *Monitor) Run(ctx context.Context) error {
 	defer c.updateTimestamps()
+	c.lastResults = nil
+
+	mw := c.ActiveMaintenance(time.Now())
+	if mw != nil && mw.Mode == maintenance.SkipChecksMode {
+		log.Debugf("Skipping %s during maintenance %s", c.Name, mw)
+		return nil
+	}
 
 	var jm MonitorInterface
 	switch c.Type {
@@ -85,6 +332,20 @@ func (c *Monitor) Run() error {
 			log.Fatal("Config key 'render_server_urn' is missing or empty, required for http_render type monitors.")
 		}
 		jm = NewHttpRenderMonitor(c.RenderServerURN)
+	case TlsMonitorType:
+		jm = &TlsMonitor{}
+	case TransactionMonitorType:
+		jm = &TransactionMonitor{}
+	case TcpMonitorType:
+		jm = &TcpMonitor{}
+	case DnsMonitorType:
+		jm = &DnsMonitor{}
+	case ReachabilityMonitorType:
+		jm = &ReachabilityMonitor{}
+	case GrpcMonitorType:
+		jm = &GrpcMonitor{}
+	case WebSocketMonitorType:
+		jm = &WebSocketMonitor{}
 	case "":
 		jm = &HttpMonitor{}
 	default:
@@ -93,13 +354,16 @@ func (c *Monitor) Run() error {
 	if c.Type == HttpMonitorType {
 		jm = &HttpMonitor{}
 	}
-	result, err := jm.Check(*c)
+	result, err := jm.Check(ctx, *c)
 	if err != nil {
+		c.consecutiveErrors++
 		return err
 	}
+	c.consecutiveErrors = 0
 	if result == nil {
 		return fmt.Errorf("empty results from Monitor")
 	}
+	c.lastResults = result
 
 	for _, result := range result.Results {
 		log.Debugf("%s: %t (err: %v)", result.ContentChecker, result.Result, result.Err)
@@ -113,13 +377,23 @@ func (c *Monitor) Run() error {
 		endResult = result.AllTrue()
 	}
 
+	if endResult != c.LastSeenState && mw != nil {
+		// Keep the last seen state so the change is notified after the
+		// maintenance if it persists.
+		log.Infof("State change for %s muted during maintenance %s: %t", c.Name, mw, endResult)
+		return nil
+	}
+
 	if endResult != c.LastSeenState {
 		log.Debugf("%s %s: %t", c.Name, c.Url, endResult)
-		log.Infof("State change for %s: %t", c.Name, result)
+		log.Infof("State change for %s: %t", c.Name, endResult)
 		c.LastSeenState = endResult
+		if c.Scheduler != nil && c.Scheduler.Burst != nil {
+			c.burstUntil = time.Now().Add(c.Scheduler.Burst.Duration)
+		}
 		for _, n := range c.Notifiers {
 			log.Debugf("Sending notification to '%s'...", n.Notifier.Name())
-			err := n.Notifier.Notify(c.Name, c.DisplayUrl, result)
+			err := n.Notifier.Notify(ctx, c.Name, c.DisplayUrl, result)
 			if err != nil {
 				log.Warn(err)
 			}
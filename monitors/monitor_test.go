@@ -1,6 +1,7 @@
 package monitors_test
 
 import (
+	"context"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"net/http"
@@ -8,9 +9,11 @@ import (
 	"testing"
 	"time"
 	"website-monitor/content_checkers"
+	"website-monitor/maintenance"
 	"website-monitor/monitors"
 	"website-monitor/notifiers"
 	"website-monitor/scheduler"
+	"website-monitor/state"
 )
 
 func SlackNotifierWithoutError(name string, options map[string]string) *notifiers.SlackNotifier {
@@ -44,7 +47,7 @@ func TestHttpMonitor_CheckNotification(t *testing.T) {
 			name: "send notification when state changes",
 			check: monitors.Monitor{
 				Name:               "Test notifications",
-				ExpectedStatusCode: 200,
+				ExpectedStatusCode: content_checkers.NewStatusCodes(200),
 				ContentChecks: []content_checkers.ContentCheckerHolder{
 					{
 						content_checkers.NewRegexChecker("regex", "sort of text", true),
@@ -64,7 +67,7 @@ func TestHttpMonitor_CheckNotification(t *testing.T) {
 			name: "dont send notification when state is same",
 			check: monitors.Monitor{
 				Name:               "Test notifications",
-				ExpectedStatusCode: 200,
+				ExpectedStatusCode: content_checkers.NewStatusCodes(200),
 				ContentChecks: []content_checkers.ContentCheckerHolder{
 					{
 						content_checkers.NewRegexChecker("regex", "sort of text", true),
@@ -80,6 +83,54 @@ func TestHttpMonitor_CheckNotification(t *testing.T) {
 			},
 			notificationCount: 0,
 		},
+		{
+			name: "dont send notification when muted by maintenance",
+			check: monitors.Monitor{
+				Name:               "Test notifications",
+				ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+				ContentChecks: []content_checkers.ContentCheckerHolder{
+					{ContentChecker: content_checkers.NewRegexChecker("regex", "sort of text", true)},
+				},
+				Notifiers: []notifiers.NotifierHolder{
+					{Notifier: SlackNotifierWithoutError("Slack", map[string]string{"webhook": slackServer.URL})},
+				},
+				Maintenance: maintenance.Windows{
+					{
+						Name: "Muted",
+						Mode: maintenance.MuteNotificationsMode,
+						From: time.Now().Add(-time.Hour),
+						To:   time.Now().Add(time.Hour),
+					},
+				},
+				Scheduler:     scheduler.NewScheduler(time.Duration(30)*time.Second, &intZero, nil, nil),
+				LastSeenState: false,
+			},
+			notificationCount: 0,
+		},
+		{
+			name: "send notification when maintenance has ended",
+			check: monitors.Monitor{
+				Name:               "Test notifications",
+				ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+				ContentChecks: []content_checkers.ContentCheckerHolder{
+					{ContentChecker: content_checkers.NewRegexChecker("regex", "sort of text", true)},
+				},
+				Notifiers: []notifiers.NotifierHolder{
+					{Notifier: SlackNotifierWithoutError("Slack", map[string]string{"webhook": slackServer.URL})},
+				},
+				Maintenance: maintenance.Windows{
+					{
+						Name: "Ended",
+						Mode: maintenance.MuteNotificationsMode,
+						From: time.Now().Add(-2 * time.Hour),
+						To:   time.Now().Add(-time.Hour),
+					},
+				},
+				Scheduler:     scheduler.NewScheduler(time.Duration(30)*time.Second, &intZero, nil, nil),
+				LastSeenState: false,
+			},
+			notificationCount: 1,
+		},
 	}
 	log.SetLevel(log.ErrorLevel)
 
@@ -94,7 +145,7 @@ func TestHttpMonitor_CheckNotification(t *testing.T) {
 			notificationCount = 0
 
 			test.check.Url = contentServer.URL
-			err := test.check.Run()
+			err := test.check.Run(context.Background())
 			if err != nil {
 				t.Errorf("got err: %v, expected %v", err, nil)
 			}
@@ -105,4 +156,263 @@ func TestHttpMonitor_CheckNotification(t *testing.T) {
 		})
 	}
 
-}
\ No newline at end of file
+}
+
+func TestMonitor_RunSkippedByMaintenance(t *testing.T) {
+	webCalls := 0
+	contentServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			webCalls++
+			_, _ = fmt.Fprintln(w, "This is some sort of text.")
+		}))
+	defer contentServer.Close()
+
+	intZero := 0
+	check := monitors.Monitor{
+		Name:               "Test maintenance",
+		Url:                contentServer.URL,
+		ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+		ContentChecks: []content_checkers.ContentCheckerHolder{
+			{ContentChecker: content_checkers.NewRegexChecker("regex", "sort of text", true)},
+		},
+		Maintenance: maintenance.Windows{
+			{
+				Name: "Skipped",
+				Mode: maintenance.SkipChecksMode,
+				From: time.Now().Add(-time.Hour),
+				To:   time.Now().Add(time.Hour),
+			},
+		},
+		Scheduler: scheduler.NewScheduler(time.Duration(30)*time.Second, &intZero, nil, nil),
+	}
+
+	if err := check.Run(context.Background()); err != nil {
+		t.Errorf("got err: %v, expected %v", err, nil)
+	}
+	if webCalls != 0 {
+		t.Errorf("webCalls; got %d, expected %d", webCalls, 0)
+	}
+	if check.NextCheckAt().IsZero() {
+		t.Error("expected next check to be scheduled")
+	}
+}
+func TestMonitor_RunBurstAndBackoff(t *testing.T) {
+	contentServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintln(w, "This is some sort of text.")
+		}))
+	defer contentServer.Close()
+
+	intZero := 0
+	s := scheduler.NewScheduler(time.Hour, &intZero, nil, nil)
+	s.Burst = &scheduler.Burst{Interval: 10 * time.Second, Duration: 5 * time.Minute}
+	s.ErrorBackoff = &scheduler.Backoff{Multiplier: 2}
+
+	check := monitors.Monitor{
+		Name:               "Test burst",
+		Url:                contentServer.URL,
+		ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+		ContentChecks: []content_checkers.ContentCheckerHolder{
+			{ContentChecker: content_checkers.NewRegexChecker("regex", "sort of text", true)},
+		},
+		Scheduler: s,
+	}
+
+	within := func(got time.Time, expected time.Duration) bool {
+		d := time.Until(got)
+		return d > expected-5*time.Second && d <= expected
+	}
+
+	if err := check.Run(context.Background()); err != nil {
+		t.Fatalf("got err: %v, expected %v", err, nil)
+	}
+	if !within(check.NextCheckAt(), 10*time.Second) {
+		t.Errorf("after state change got next check at %s, expected burst interval", check.NextCheckAt())
+	}
+
+	check.Url = "http://127.0.0.1:0/"
+	if err := check.Run(context.Background()); err == nil {
+		t.Fatal("expected err, got nil")
+	}
+	if !within(check.NextCheckAt(), 2*time.Hour) {
+		t.Errorf("after first error got next check at %s, expected 2h", check.NextCheckAt())
+	}
+	if err := check.Run(context.Background()); err == nil {
+		t.Fatal("expected err, got nil")
+	}
+	if !within(check.NextCheckAt(), 4*time.Hour) {
+		t.Errorf("after second error got next check at %s, expected 4h", check.NextCheckAt())
+	}
+
+	check.Url = contentServer.URL
+	if err := check.Run(context.Background()); err != nil {
+		t.Fatalf("got err: %v, expected %v", err, nil)
+	}
+	if !within(check.NextCheckAt(), 10*time.Second) {
+		t.Errorf("after recovering within burst got next check at %s, expected burst interval", check.NextCheckAt())
+	}
+}
+
+func TestMonitor_Start(t *testing.T) {
+	now := time.Date(2021, 02, 17, 10, 00, 00, 00, time.UTC)
+	intZero := 0
+
+	tests := []struct {
+		name          string
+		policy        monitors.StartupPolicy
+		saved         *state.MonitorState
+		earliest      time.Time
+		latest        time.Time
+		lastSeenState bool
+	}{
+		{
+			name:     "default is immediate",
+			earliest: now,
+			latest:   now,
+		},
+		{
+			name:     "immediate",
+			policy:   monitors.ImmediateStartup,
+			saved:    &state.MonitorState{NextCheckAt: now.Add(time.Minute), LastSeenState: true},
+			earliest: now,
+			latest:   now,
+		},
+		{
+			name:     "staggered",
+			policy:   monitors.StaggeredStartup,
+			earliest: now,
+			latest:   now.Add(time.Hour),
+		},
+		{
+			name:          "resume",
+			policy:        monitors.ResumeStartup,
+			saved:         &state.MonitorState{NextCheckAt: now.Add(time.Minute), LastSeenState: true},
+			earliest:      now.Add(time.Minute),
+			latest:        now.Add(time.Minute),
+			lastSeenState: true,
+		},
+		{
+			name:          "resume overdue is staggered",
+			policy:        monitors.ResumeStartup,
+			saved:         &state.MonitorState{NextCheckAt: now.Add(-time.Minute), LastSeenState: true},
+			earliest:      now,
+			latest:        now.Add(time.Hour),
+			lastSeenState: true,
+		},
+		{
+			name:     "resume without saved state is staggered",
+			policy:   monitors.ResumeStartup,
+			earliest: now,
+			latest:   now.Add(time.Hour),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := monitors.Monitor{
+				Name:      test.name,
+				Scheduler: scheduler.NewScheduler(time.Hour, &intZero, nil, nil),
+			}
+			m.Start(test.policy, now, test.saved)
+
+			if m.NextCheckAt().Before(test.earliest) || m.NextCheckAt().After(test.latest) {
+				t.Errorf("got next check %s, expected between %s and %s", m.NextCheckAt(), test.earliest, test.latest)
+			}
+			if m.LastSeenState != test.lastSeenState {
+				t.Errorf("got last seen state %t, expected %t", m.LastSeenState, test.lastSeenState)
+			}
+		})
+	}
+}
+
+func TestMonitor_State(t *testing.T) {
+	intZero := 0
+	m := monitors.Monitor{
+		Name:      "state",
+		Scheduler: scheduler.NewScheduler(time.Hour, &intZero, nil, nil),
+	}
+
+	st := state.MonitorState{
+		LastCheckedAt: time.Date(2021, 02, 17, 10, 00, 00, 00, time.UTC),
+		NextCheckAt:   time.Date(2021, 02, 17, 11, 00, 00, 00, time.UTC),
+		LastSeenState: true,
+	}
+	m.Restore(st)
+
+	if m.State() != st {
+		t.Errorf("got %+v, expected %+v", m.State(), st)
+	}
+}
+
+func TestMonitor_Origin(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://example.com/some/page", expected: "https://example.com:443"},
+		{url: "http://example.com/", expected: "http://example.com:80"},
+		{url: "http://Example.com:8080/feed.json", expected: "http://Example.com:8080"},
+		{url: "https://[::1]/", expected: "https://[::1]:443"},
+		{url: "", expected: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			m := monitors.Monitor{Url: test.url}
+			if got := m.Origin(); got != test.expected {
+				t.Errorf("got '%s', expected '%s'", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestMonitor_Equal(t *testing.T) {
+	newMonitor := func() *monitors.Monitor {
+		return &monitors.Monitor{
+			Name:               "equal",
+			Url:                "https://example.com/",
+			ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+			Headers:            map[string]string{"Referer": "https://example.com/"},
+			ContentChecks: []content_checkers.ContentCheckerHolder{
+				{ContentChecker: content_checkers.NewRegexChecker("regex", "sort of text", true)},
+			},
+			Notifiers: []notifiers.NotifierHolder{
+				{Notifier: SlackNotifierWithoutError("Slack", map[string]string{"webhook": "https://hooks.example.com/"})},
+			},
+			Scheduler: scheduler.NewScheduler(time.Hour, nil, nil, nil),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		change   func(m *monitors.Monitor)
+		expected bool
+	}{
+		{name: "same config", change: func(m *monitors.Monitor) {}, expected: true},
+		{name: "different status", change: func(m *monitors.Monitor) {
+			m.LastSeenState = true
+			m.Restore(state.MonitorState{NextCheckAt: time.Now()})
+		}, expected: true},
+		{name: "different url", change: func(m *monitors.Monitor) { m.Url = "https://example.org/" }, expected: false},
+		{name: "different header", change: func(m *monitors.Monitor) { m.Headers["Accept"] = "text/html" }, expected: false},
+		{name: "different schedule", change: func(m *monitors.Monitor) {
+			m.Scheduler = scheduler.NewScheduler(time.Minute, nil, nil, nil)
+		}, expected: false},
+		{name: "different check", change: func(m *monitors.Monitor) {
+			m.ContentChecks[0].ContentChecker = content_checkers.NewRegexChecker("regex", "other text", true)
+		}, expected: false},
+		{name: "different notifier", change: func(m *monitors.Monitor) {
+			m.Notifiers[0].Notifier = PushSaferNotifierWithoutError("Slack", map[string]string{"private_key": "key"})
+		}, expected: false},
+		{name: "added maintenance", change: func(m *monitors.Monitor) {
+			m.Maintenance = maintenance.Windows{{Name: "Upgrade", Mode: maintenance.SkipChecksMode}}
+		}, expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := newMonitor()
+			test.change(m)
+			if got := newMonitor().Equal(m); got != test.expected {
+				t.Errorf("got %t, expected %t", got, test.expected)
+			}
+		})
+	}
+}
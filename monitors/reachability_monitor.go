@@ -0,0 +1,105 @@
+package monitors
+
+import (
+	"context"
+	"net"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/result"
+)
+
+const (
+	// DefaultProbes is the number of connects per check.
+	DefaultProbes = 5
+	// DefaultProbeInterval is the time between the start of two probes.
+	DefaultProbeInterval = 100 * time.Millisecond
+)
+
+// ReachabilityMonitor connects to a port a number of times per check, like
+// ping without needing raw sockets, and checks the loss and latency.
+type ReachabilityMonitor struct{}
+
+// Check resolves the host once, so the latencies are those of connecting.
+// Failing to resolve it is an error, failed connects are lost probes.
+func (rm *ReachabilityMonitor) Check(ctx context.Context, check Monitor) (*result.Results, error) {
+	addr, err := tcpAddress(check.Url)
+	if err != nil {
+		return nil, err
+	}
+	host, port, _ := net.SplitHostPort(addr)
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	addr = net.JoinHostPort(ips[0].String(), port)
+
+	count, interval := check.ProbeCount, check.ProbeInterval
+	if count == 0 {
+		count = DefaultProbes
+	}
+	if interval == 0 {
+		interval = DefaultProbeInterval
+	}
+	timeout := check.ConnectTimeout
+	if timeout == 0 {
+		timeout = check.timeout(DefaultHttpTimeout)
+	}
+
+	probes := &content_checkers.Probes{}
+	started := time.Now()
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+
+		d, err := probe(ctx, addr, timeout)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		probes.Add(err == nil, d)
+	}
+
+	timings := content_checkers.Timings{
+		Connect: probes.Avg,
+		Total:   time.Since(started),
+	}
+	response := &content_checkers.Response{
+		FinalURL: check.Url,
+		Timings:  timings,
+		Probes:   probes,
+	}
+
+	checks := check.ContentChecks
+	if len(checks) == 0 {
+		checks = content_checkers.DefaultReachabilityChecks()
+	}
+
+	return &result.Results{
+		Results:  runChecks(checks, response, nil),
+		Attempts: 1,
+		Timings:  timings,
+		Probes:   probes,
+	}, nil
+}
+
+// probe connects to addr and returns how long it took.
+func probe(ctx context.Context, addr string, timeout time.Duration) (time.Duration, error) {
+	d := &net.Dialer{Timeout: timeout}
+	started := time.Now()
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return 0, err
+	}
+	elapsed := time.Since(started)
+	conn.Close()
+
+	return elapsed, nil
+}
+
+func (rm *ReachabilityMonitor) Type() string {
+	return "ReachabilityMonitor"
+}
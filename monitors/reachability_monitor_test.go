@@ -0,0 +1,89 @@
+package monitors_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/monitors"
+)
+
+func TestReachabilityMonitor_Check(t *testing.T) {
+	open, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer open.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	latency, _ := content_checkers.NewReachabilityChecker("fast", "max", "1s")
+	slow, _ := content_checkers.NewReachabilityChecker("too fast", "avg", "1ns")
+	tests := []struct {
+		name     string
+		addr     string
+		checks   []content_checkers.ContentCheckerHolder
+		received int
+		expected []bool
+	}{
+		{"reachable", open.Addr().String(), nil, 3, []bool{true}},
+		{"unreachable", "tcp://" + closed.Addr().String(), nil, 0, []bool{false}},
+		{"latency", open.Addr().String(), []content_checkers.ContentCheckerHolder{{ContentChecker: latency}, {ContentChecker: slow}}, 3, []bool{true, false}},
+		{"latency unreachable", closed.Addr().String(), []content_checkers.ContentCheckerHolder{{ContentChecker: latency}}, 0, []bool{false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := monitors.Monitor{
+				Name:          "reachability",
+				Url:           tt.addr,
+				Type:          monitors.ReachabilityMonitorType,
+				ProbeCount:    3,
+				ProbeInterval: time.Millisecond,
+				ContentChecks: tt.checks,
+			}
+			rm := monitors.ReachabilityMonitor{}
+			res, err := rm.Check(context.Background(), ch)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			if res.Probes.Sent != 3 || res.Probes.Received != tt.received {
+				t.Errorf("got %d of %d probes received, expected %d of 3", res.Probes.Received, res.Probes.Sent, tt.received)
+			}
+			if len(res.Results) != len(tt.expected) {
+				t.Fatalf("got %d results, expected %d", len(res.Results), len(tt.expected))
+			}
+			for i, r := range res.Results {
+				if r.Result != tt.expected[i] {
+					t.Errorf("%s: got %t (err: %v), expected %t", r.ContentChecker, r.Result, r.Err, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReachabilityMonitor_CheckCancelled(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ch := monitors.Monitor{
+		Name:          "reachability",
+		Url:           l.Addr().String(),
+		Type:          monitors.ReachabilityMonitorType,
+		ProbeCount:    100,
+		ProbeInterval: 10 * time.Millisecond,
+	}
+	rm := monitors.ReachabilityMonitor{}
+	if _, err := rm.Check(ctx, ch); err == nil {
+		t.Errorf("got nil, expected the check to stop when cancelled")
+	}
+}
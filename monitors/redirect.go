@@ -0,0 +1,61 @@
+package monitors
+
+import (
+	"fmt"
+	"net/http"
+	"website-monitor/content_checkers"
+)
+
+// DefaultMaxRedirects is how many redirects are followed unless
+// follow_redirects says otherwise, the same as for net/http.
+const DefaultMaxRedirects = 10
+
+// RedirectLimit is how many redirects a monitor follows, configured as true
+// for DefaultMaxRedirects, false for none or a number.
+type RedirectLimit int
+
+func (rl *RedirectLimit) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var follow bool
+	if err := unmarshal(&follow); err == nil {
+		*rl = 0
+		if follow {
+			*rl = DefaultMaxRedirects
+		}
+		return nil
+	}
+
+	var max int
+	if err := unmarshal(&max); err != nil || max < 0 {
+		return fmt.Errorf("invalid follow_redirects, expected true, false or a number of redirects")
+	}
+	*rl = RedirectLimit(max)
+
+	return nil
+}
+
+func (c *Monitor) maxRedirects() int {
+	if c.FollowRedirects == nil {
+		return DefaultMaxRedirects
+	}
+
+	return int(*c.FollowRedirects)
+}
+
+// checkRedirect stops following redirects once the monitor's limit is
+// reached, returning the last redirect response to check instead of an
+// error.
+func (c *Monitor) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > c.maxRedirects() {
+		return http.ErrUseLastResponse
+	}
+
+	return nil
+}
+
+func newRedirect(resp *http.Response) content_checkers.Redirect {
+	return content_checkers.Redirect{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Location:   resp.Header.Get("Location"),
+	}
+}
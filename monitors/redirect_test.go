@@ -0,0 +1,103 @@
+package monitors_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"website-monitor/content_checkers"
+	"website-monitor/monitors"
+)
+
+func redirectChecker(name, path, regex string) content_checkers.ContentCheckerHolder {
+	c, _ := content_checkers.NewRedirectChecker(name, path, regex, true)
+
+	return content_checkers.ContentCheckerHolder{ContentChecker: c}
+}
+
+func TestHttpMonitor_CheckRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/product", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/product/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/product/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/sold-out", http.StatusFound)
+	})
+	mux.HandleFunc("/sold-out", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(w, "Sold out")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	limit := func(max int) *monitors.RedirectLimit {
+		rl := monitors.RedirectLimit(max)
+		return &rl
+	}
+
+	tests := []struct {
+		name            string
+		followRedirects *monitors.RedirectLimit
+		checks          []content_checkers.ContentCheckerHolder
+		result          bool
+	}{
+		{
+			name:   "follows by default",
+			checks: []content_checkers.ContentCheckerHolder{redirectChecker("final", "final", "/sold-out$")},
+			result: true,
+		},
+		{
+			name: "chain is available",
+			checks: []content_checkers.ContentCheckerHolder{
+				redirectChecker("first", "1", "^/product/$"),
+				redirectChecker("second", "2", "^/sold-out$"),
+			},
+			result: true,
+		},
+		{
+			name:            "not following",
+			followRedirects: limit(0),
+			checks: []content_checkers.ContentCheckerHolder{
+				redirectChecker("final", "final", "/product$"),
+				redirectChecker("first", "1", "^/product/$"),
+			},
+			// The redirect itself isn't an expected status code.
+			result: false,
+		},
+		{
+			name:            "limited",
+			followRedirects: limit(1),
+			checks: []content_checkers.ContentCheckerHolder{
+				redirectChecker("final", "final", "/product/$"),
+				redirectChecker("second", "2", "^/sold-out$"),
+			},
+			result: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ch := monitors.Monitor{
+				Name:               test.name,
+				Url:                ts.URL + "/product",
+				ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+				FollowRedirects:    test.followRedirects,
+				ContentChecks:      test.checks,
+			}
+			hm := monitors.HttpMonitor{}
+			res, err := hm.Check(context.Background(), ch)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			if res.AllTrue() != test.result {
+				t.Errorf("got %t, expected %t", res.AllTrue(), test.result)
+			}
+			// All redirect checks pass, only the status code differs.
+			for _, r := range res.Results[1:] {
+				if !r.Result {
+					t.Errorf("%s: got false (err: %v), expected true", r.ContentChecker, r.Err)
+				}
+			}
+		})
+	}
+}
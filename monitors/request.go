@@ -0,0 +1,181 @@
+package monitors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"website-monitor/content_checkers"
+)
+
+// Validate checks that the request config of the monitor is consistent.
+func (c *Monitor) Validate() error {
+	bodies := 0
+	for _, set := range []bool{c.Body != "", c.BodyFile != "", c.Form != nil, c.JsonBody != nil} {
+		if set {
+			bodies++
+		}
+	}
+	if bodies > 1 {
+		return fmt.Errorf("monitor '%s' can only have one of body, body_file, form and json_body", c.Name)
+	}
+
+	if c.Timeout < 0 || c.ConnectTimeout < 0 || c.RetryBackoff < 0 {
+		return fmt.Errorf("monitor '%s' has a negative timeout or retry_backoff", c.Name)
+	}
+	if c.Retries < 0 {
+		return fmt.Errorf("monitor '%s' has invalid retries: %d", c.Name, c.Retries)
+	}
+
+	if c.TLS != nil {
+		if err := c.TLS.validate(); err != nil {
+			return fmt.Errorf("monitor '%s': %v", c.Name, err)
+		}
+	}
+	if err := validateProxy(c.Proxy); err != nil {
+		return fmt.Errorf("monitor '%s': %v", c.Name, err)
+	}
+	if c.Auth != nil {
+		if err := c.Auth.Validate(); err != nil {
+			return fmt.Errorf("monitor '%s': %v", c.Name, err)
+		}
+	}
+
+	if c.MaxMessages < 0 || c.CollectFor < 0 {
+		return fmt.Errorf("monitor '%s' has negative max_messages or collect_for", c.Name)
+	}
+	switch c.MessageMatch {
+	case "", AnyMessage, AllMessages:
+	default:
+		return fmt.Errorf("monitor '%s' has invalid message_match '%s', expected any or all", c.Name, c.MessageMatch)
+	}
+
+	if c.ProbeCount < 0 || c.ProbeInterval < 0 {
+		return fmt.Errorf("monitor '%s' has negative probes or probe_interval", c.Name)
+	}
+
+	if c.Type == TcpMonitorType || c.Type == ReachabilityMonitorType || c.Type == GrpcMonitorType {
+		if _, err := tcpAddress(c.Url); err != nil {
+			return fmt.Errorf("monitor '%s': %v", c.Name, err)
+		}
+	}
+	if c.Type == DnsMonitorType {
+		if _, err := c.recordType(); err != nil {
+			return fmt.Errorf("monitor '%s': %v", c.Name, err)
+		}
+	}
+	if c.Type == HttpRenderMonitorType {
+		for _, check := range c.ContentChecks {
+			if rt, ok := check.ContentChecker.(*content_checkers.ResponseTimeChecker); ok && rt.Phase() != content_checkers.TotalPhase {
+				return fmt.Errorf("monitor '%s': %s can only check the total time of rendered pages", c.Name, rt)
+			}
+		}
+	}
+	if c.Type == TransactionMonitorType && len(c.Steps) == 0 {
+		return fmt.Errorf("transaction monitor '%s' has no steps", c.Name)
+	}
+	for _, step := range c.Steps {
+		if err := step.validate(); err != nil {
+			return fmt.Errorf("monitor '%s': %v", c.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// RequestMethod returns the configured method, defaulting to POST when the
+// monitor sends a body and GET otherwise.
+func (c *Monitor) RequestMethod() string {
+	if c.Method != "" {
+		return strings.ToUpper(c.Method)
+	}
+	if c.Body != "" || c.BodyFile != "" || c.Form != nil || c.JsonBody != nil {
+		return http.MethodPost
+	}
+
+	return http.MethodGet
+}
+
+// requestBody returns the body to send and its content type, or a nil
+// reader if the monitor doesn't send a body.
+func (c *Monitor) requestBody() (io.Reader, string, error) {
+	switch {
+	case c.Body != "":
+		return strings.NewReader(c.Body), http.DetectContentType([]byte(c.Body)), nil
+	case c.BodyFile != "":
+		data, err := ioutil.ReadFile(c.BodyFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("error reading body_file: %v", err)
+		}
+		return bytes.NewReader(data), http.DetectContentType(data), nil
+	case c.Form != nil:
+		return strings.NewReader(formValues(c.Form).Encode()), "application/x-www-form-urlencoded", nil
+	case c.JsonBody != nil:
+		data, err := json.Marshal(c.JsonBody)
+		if err != nil {
+			return nil, "", fmt.Errorf("error encoding json_body: %v", err)
+		}
+		return bytes.NewReader(data), "application/json", nil
+	}
+
+	return nil, "", nil
+}
+
+func formValues(form map[string]string) url.Values {
+	values := url.Values{}
+	for k, v := range form {
+		values.Set(k, v)
+	}
+
+	return values
+}
+
+// NewRequest builds the request for the monitor. A form on a GET request is
+// sent as the query string. A Content-Type header in the monitor's headers
+// overrides the one derived from the body.
+func (c *Monitor) NewRequest(ctx context.Context) (*http.Request, error) {
+	method := c.RequestMethod()
+	target := c.Url
+
+	var body io.Reader
+	var contentType string
+	if method == http.MethodGet && c.Form != nil {
+		u, err := url.Parse(c.Url)
+		if err != nil {
+			return nil, err
+		}
+		q := u.Query()
+		for k, v := range c.Form {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+		target = u.String()
+	} else {
+		var err error
+		if body, contentType, err = c.requestBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range c.Headers {
+		if http.CanonicalHeaderKey(k) == "Content-Type" {
+			req.Header.Set(k, v)
+			continue
+		}
+		req.Header.Add(k, v)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,134 @@
+package monitors_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"website-monitor/content_checkers"
+	"website-monitor/monitors"
+)
+
+func TestHttpMonitor_CheckRequest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "request")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bodyFile := filepath.Join(dir, "query.graphql")
+	if err := ioutil.WriteFile(bodyFile, []byte(`{"query": "{ status }"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		check       monitors.Monitor
+		method      string
+		contentType string
+		body        string
+		query       string
+	}{
+		{
+			name:   "plain get",
+			check:  monitors.Monitor{},
+			method: http.MethodGet,
+		},
+		{
+			name:        "body defaults to post",
+			check:       monitors.Monitor{Body: "q=status"},
+			method:      http.MethodPost,
+			contentType: "text/plain; charset=utf-8",
+			body:        "q=status",
+		},
+		{
+			name:        "body with method and content type",
+			check:       monitors.Monitor{Method: "put", Body: "<status/>", Headers: map[string]string{"content-type": "application/xml"}},
+			method:      http.MethodPut,
+			contentType: "application/xml",
+			body:        "<status/>",
+		},
+		{
+			name:        "body file",
+			check:       monitors.Monitor{BodyFile: bodyFile},
+			method:      http.MethodPost,
+			contentType: "text/plain; charset=utf-8",
+			body:        `{"query": "{ status }"}`,
+		},
+		{
+			name:        "form",
+			check:       monitors.Monitor{Form: map[string]string{"q": "status", "lang": "en"}},
+			method:      http.MethodPost,
+			contentType: "application/x-www-form-urlencoded",
+			body:        "lang=en&q=status",
+		},
+		{
+			name:   "form on get is sent as query",
+			check:  monitors.Monitor{Method: http.MethodGet, Form: map[string]string{"q": "status"}},
+			method: http.MethodGet,
+			query:  "q=status",
+		},
+		{
+			name:        "json body",
+			check:       monitors.Monitor{JsonBody: map[string]interface{}{"query": "{ status }", "variables": map[string]interface{}{"id": 1}}},
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        `{"query":"{ status }","variables":{"id":1}}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var method, contentType, body, query string
+			ts := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					data, _ := ioutil.ReadAll(r.Body)
+					method, contentType, body, query = r.Method, r.Header.Get("Content-Type"), string(data), r.URL.RawQuery
+				}))
+			defer ts.Close()
+
+			test.check.Url = ts.URL
+			test.check.ExpectedStatusCode = content_checkers.NewStatusCodes(200)
+			hm := monitors.HttpMonitor{}
+			if _, err := hm.Check(context.Background(), test.check); err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			if method != test.method {
+				t.Errorf("got method %s, expected %s", method, test.method)
+			}
+			if contentType != test.contentType {
+				t.Errorf("got content type '%s', expected '%s'", contentType, test.contentType)
+			}
+			if body != test.body {
+				t.Errorf("got body '%s', expected '%s'", body, test.body)
+			}
+			if query != test.query {
+				t.Errorf("got query '%s', expected '%s'", query, test.query)
+			}
+		})
+	}
+}
+
+func TestMonitor_Validate(t *testing.T) {
+	m := monitors.Monitor{Name: "both", Body: "q=status", Form: map[string]string{"q": "status"}}
+	if err := m.Validate(); err == nil {
+		t.Error("got nil, expected an error for more than one body")
+	}
+
+	m = monitors.Monitor{Name: "one", JsonBody: map[string]interface{}{"q": "status"}}
+	if err := m.Validate(); err != nil {
+		t.Errorf("got err %v, expected nil", err)
+	}
+
+	ttfb, _ := content_checkers.NewResponseTimeChecker("ttfb", "ttfb", "1s")
+	m = monitors.Monitor{
+		Name:          "render",
+		Type:          monitors.HttpRenderMonitorType,
+		ContentChecks: []content_checkers.ContentCheckerHolder{{ContentChecker: ttfb}},
+	}
+	if err := m.Validate(); err == nil {
+		t.Error("got nil, expected an error for a phase rendered pages don't have")
+	}
+}
@@ -0,0 +1,67 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultHttpTimeout   = 5 * time.Second
+	DefaultRenderTimeout = 10 * time.Second
+)
+
+// attemptFunc makes one attempt at a check, last is set if it won't be
+// retried. retryable reports whether a failed attempt is worth trying again,
+// as for transport errors.
+type attemptFunc func(last bool) (retryable bool, err error)
+
+// retry calls attempt until it succeeds, fails with an error which isn't
+// retryable or the monitor's retries are used up. It waits retry_backoff
+// before the first retry, doubling the wait for each one after that. It
+// returns the number of attempts made.
+func (c *Monitor) retry(ctx context.Context, attempt attemptFunc) (int, error) {
+	wait := c.RetryBackoff
+	for attempts := 1; ; attempts++ {
+		retryable, err := attempt(attempts > c.Retries)
+		if err == nil {
+			return attempts, nil
+		}
+		if !retryable || attempts > c.Retries {
+			if attempts > 1 {
+				err = fmt.Errorf("%v (after %d attempts)", err, attempts)
+			}
+			return attempts, err
+		}
+
+		log.Debugf("Retrying %s in %s after attempt %d failed: %v", c.Name, wait, attempts, err)
+		select {
+		case <-ctx.Done():
+			return attempts, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// shouldRetryStatus reports whether a response with the status code is
+// retried instead of checked.
+func (c *Monitor) shouldRetryStatus(code int) bool {
+	for _, rc := range c.RetryStatusCodes {
+		if rc == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Monitor) timeout(def time.Duration) time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+
+	return def
+}
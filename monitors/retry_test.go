@@ -0,0 +1,135 @@
+package monitors_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/monitors"
+)
+
+// flakyServer answers with failStatus for the first failures requests.
+type flakyServer struct {
+	mu         sync.Mutex
+	requests   int
+	failures   int
+	failStatus int
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	fail := s.requests <= s.failures
+	s.mu.Unlock()
+
+	if fail {
+		w.WriteHeader(s.failStatus)
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Some sort of text test.")
+}
+
+func TestHttpMonitor_CheckRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		failures         int
+		retries          int
+		retryStatusCodes []int
+		requests         int
+		result           bool
+	}{
+		{name: "no retries", failures: 1, retries: 0, retryStatusCodes: []int{503}, requests: 1, result: false},
+		{name: "retried until success", failures: 2, retries: 2, retryStatusCodes: []int{503}, requests: 3, result: true},
+		{name: "retries used up", failures: 3, retries: 2, retryStatusCodes: []int{503}, requests: 3, result: false},
+		{name: "status not retried", failures: 1, retries: 2, requests: 1, result: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := &flakyServer{failures: test.failures, failStatus: http.StatusServiceUnavailable}
+			ts := httptest.NewServer(srv)
+			defer ts.Close()
+
+			ch := monitors.Monitor{
+				Name:               test.name,
+				Url:                ts.URL,
+				ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+				ContentChecks: []content_checkers.ContentCheckerHolder{
+					{ContentChecker: content_checkers.NewRegexChecker("regex", "sort of text", true)},
+				},
+				Retries:          test.retries,
+				RetryBackoff:     time.Millisecond,
+				RetryStatusCodes: test.retryStatusCodes,
+			}
+			hm := monitors.HttpMonitor{}
+			res, err := hm.Check(context.Background(), ch)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+			if srv.requests != test.requests {
+				t.Errorf("got %d requests, expected %d", srv.requests, test.requests)
+			}
+			if res.AllTrue() != test.result {
+				t.Errorf("got result %t, expected %t", res.AllTrue(), test.result)
+			}
+			if res.Attempts != test.requests {
+				t.Errorf("got %d attempts in results, expected %d", res.Attempts, test.requests)
+			}
+		})
+	}
+}
+
+func TestHttpMonitor_CheckRetriesTransportErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := ts.URL
+	ts.Close()
+
+	ch := monitors.Monitor{
+		Name:               "closed",
+		Url:                url,
+		ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+		Retries:            2,
+		RetryBackoff:       20 * time.Millisecond,
+	}
+	hm := monitors.HttpMonitor{}
+
+	started := time.Now()
+	if _, err := hm.Check(context.Background(), ch); err == nil {
+		t.Fatal("got nil, expected an error")
+	}
+	// Waits 20ms and then 40ms between the three attempts.
+	if time.Since(started) < 60*time.Millisecond {
+		t.Errorf("returned after %s, expected it to back off between retries", time.Since(started))
+	}
+}
+
+func TestHttpMonitor_CheckTimeout(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	ch := monitors.Monitor{
+		Name:               "slow",
+		Url:                ts.URL,
+		ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+		Timeout:            50 * time.Millisecond,
+	}
+	hm := monitors.HttpMonitor{}
+
+	started := time.Now()
+	if _, err := hm.Check(context.Background(), ch); err == nil {
+		t.Fatal("got nil, expected a timeout")
+	}
+	if time.Since(started) > time.Second {
+		t.Errorf("returned after %s, expected the 50ms timeout", time.Since(started))
+	}
+}
@@ -0,0 +1,176 @@
+package monitors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/result"
+)
+
+// maxTcpResponse limits how much of a response is read.
+const maxTcpResponse = 64 * 1024
+
+// tcpReadIdle ends a response when no more data arrives for this long after
+// the first, servers like SMTP relays don't close the connection.
+const tcpReadIdle = 250 * time.Millisecond
+
+// TcpMonitor connects to a port, sends the monitor's body if it has one,
+// and checks the response like a http body.
+type TcpMonitor struct{}
+
+func (tm *TcpMonitor) Check(ctx context.Context, check Monitor) (*result.Results, error) {
+	addr, err := tcpAddress(check.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	var results *result.Results
+	attempts, err := check.retry(ctx, func(last bool) (bool, error) {
+		var err error
+		results, err = tm.attempt(ctx, check, addr)
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	results.Attempts = attempts
+
+	return results, nil
+}
+
+// attempt only reads a response if there is a payload or something to
+// check, connecting is enough otherwise.
+func (tm *TcpMonitor) attempt(ctx context.Context, check Monitor, addr string) (*result.Results, error) {
+	payload, _, err := check.requestBody()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, check.timeout(DefaultHttpTimeout))
+	defer cancel()
+
+	d := &net.Dialer{Timeout: check.ConnectTimeout}
+	started := time.Now()
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	timings := content_checkers.Timings{Connect: time.Since(started)}
+
+	// Deadlines end reads and writes, cancelling the context has to as well.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	sent := time.Now()
+	if payload != nil {
+		if _, err := io.Copy(conn, payload); err != nil {
+			return nil, fmt.Errorf("error sending body: %v", err)
+		}
+	}
+
+	var body []byte
+	if payload != nil || len(check.ContentChecks) > 0 {
+		var firstByte time.Time
+		if body, firstByte, err = readResponse(conn, deadline); err != nil {
+			return nil, err
+		}
+		// The deadline set when the check is cancelled ends reading like
+		// the timeout, but a cancelled check has no result.
+		if ctx.Err() == context.Canceled {
+			return nil, ctx.Err()
+		}
+		if !firstByte.IsZero() {
+			timings.TTFB = firstByte.Sub(sent)
+		}
+	}
+	timings.Total = time.Since(started)
+
+	response := &content_checkers.Response{
+		FinalURL: check.Url,
+		Timings:  timings,
+	}
+
+	return &result.Results{
+		Results: runChecks(check.ContentChecks, response, body),
+		Timings: timings,
+	}, nil
+}
+
+// readResponse reads until the server closes the connection, stops sending
+// for tcpReadIdle or the deadline. A server which doesn't send anything
+// before the deadline gives an empty response for the checks.
+func readResponse(conn net.Conn, deadline time.Time) ([]byte, time.Time, error) {
+	var body []byte
+	var firstByte time.Time
+	buf := make([]byte, 4096)
+	for len(body) < maxTcpResponse {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			if firstByte.IsZero() {
+				firstByte = time.Now()
+			}
+			body = append(body, buf[:n]...)
+			idle := time.Now().Add(tcpReadIdle)
+			if idle.Before(deadline) {
+				_ = conn.SetReadDeadline(idle)
+			}
+		}
+
+		var netErr net.Error
+		switch {
+		case err == nil:
+		case err == io.EOF:
+			return body, firstByte, nil
+		case errors.As(err, &netErr) && netErr.Timeout():
+			return body, firstByte, nil
+		default:
+			return nil, firstByte, err
+		}
+	}
+	if len(body) > maxTcpResponse {
+		body = body[:maxTcpResponse]
+	}
+
+	return body, firstByte, nil
+}
+
+// tcpAddress returns the address to dial for a url like "tcp://host:port"
+// or "host:port", which needs a port.
+func tcpAddress(target string) (string, error) {
+	host := target
+	if strings.Contains(target, "://") {
+		u, err := url.Parse(target)
+		if err != nil {
+			return "", err
+		}
+		host = u.Host
+	}
+
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		return "", fmt.Errorf("invalid tcp address '%s', expected host:port", target)
+	}
+
+	return host, nil
+}
+
+func (tm *TcpMonitor) Type() string {
+	return "TcpMonitor"
+}
@@ -0,0 +1,129 @@
+package monitors_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/monitors"
+)
+
+// tcpServer greets like an SMTP relay and answers PING, without closing the
+// connection.
+func tcpServer(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = conn.Write([]byte("220 relay.example ESMTP\r\n"))
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == "PING\r\n" {
+					_, _ = conn.Write([]byte("+PONG\r\n"))
+				}
+				time.Sleep(5 * time.Second)
+			}()
+		}
+	}()
+
+	return l
+}
+
+func TestTcpMonitor_Check(t *testing.T) {
+	l := tcpServer(t)
+	defer l.Close()
+
+	tests := []struct {
+		name     string
+		body     string
+		check    string
+		expected bool
+	}{
+		{"banner", "", "^220 .*ESMTP", true},
+		{"wrong banner", "", "^554", false},
+		{"payload", "PING\r\n", `\+PONG`, true},
+		{"connect only", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := monitors.Monitor{
+				Name:    "tcp",
+				Url:     "tcp://" + l.Addr().String(),
+				Type:    monitors.TcpMonitorType,
+				Body:    tt.body,
+				Timeout: 2 * time.Second,
+			}
+			if tt.check != "" {
+				ch.ContentChecks = []content_checkers.ContentCheckerHolder{
+					{ContentChecker: content_checkers.NewRegexChecker("response", tt.check, true)},
+				}
+			}
+			tm := monitors.TcpMonitor{}
+			started := time.Now()
+			res, err := tm.Check(context.Background(), ch)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+			if res.AllTrue() != tt.expected {
+				t.Errorf("got %t (%v), expected %t", res.AllTrue(), res.Results, tt.expected)
+			}
+			// the server keeps the connection open, reading stops when it
+			// stops sending
+			if elapsed := time.Since(started); elapsed > time.Second {
+				t.Errorf("took %s, expected reading to stop after the response", elapsed)
+			}
+		})
+	}
+}
+
+func TestTcpMonitor_CheckCancelled(t *testing.T) {
+	l := tcpServer(t)
+	defer l.Close()
+
+	ch := monitors.Monitor{
+		Name: "tcp",
+		Url:  l.Addr().String(),
+		Type: monitors.TcpMonitorType,
+		ContentChecks: []content_checkers.ContentCheckerHolder{
+			{ContentChecker: content_checkers.NewRegexChecker("banner", "^220", true)},
+		},
+	}
+	// Cancelled after the banner arrived, while waiting for more.
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	tm := monitors.TcpMonitor{}
+	if _, err := tm.Check(ctx, ch); err != context.Canceled {
+		t.Errorf("got err %v, expected the cancelled check to have no result", err)
+	}
+}
+
+func TestTcpMonitor_CheckClosedPort(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	ch := monitors.Monitor{
+		Name: "tcp",
+		Url:  addr,
+		Type: monitors.TcpMonitorType,
+	}
+	tm := monitors.TcpMonitor{}
+	if _, err := tm.Check(context.Background(), ch); err == nil {
+		t.Errorf("got nil, expected an error for the closed port")
+	}
+}
@@ -0,0 +1,117 @@
+package monitors
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/result"
+)
+
+// TlsMonitor connects to a host and checks its certificate and protocol
+// version without making a request.
+type TlsMonitor struct{}
+
+func (tm *TlsMonitor) Check(ctx context.Context, check Monitor) (*result.Results, error) {
+	addr, serverName, err := tlsAddress(check.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{}
+	if check.TLS != nil {
+		if cfg, err = check.TLS.load(); err != nil {
+			return nil, err
+		}
+		if check.TLS.ServerName != "" {
+			serverName = check.TLS.ServerName
+		}
+	}
+	// The certificate and version are verified by the checks, so problems
+	// with them are reported instead of failing the connection.
+	cfg.ServerName = serverName
+	cfg.InsecureSkipVerify = true
+	cfg.MinVersion = tls.VersionTLS10
+
+	var results *result.Results
+	attempts, err := check.retry(ctx, func(last bool) (bool, error) {
+		var err error
+		results, err = tm.attempt(ctx, check, addr, cfg)
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	results.Attempts = attempts
+
+	return results, nil
+}
+
+func (tm *TlsMonitor) attempt(ctx context.Context, check Monitor, addr string, cfg *tls.Config) (*result.Results, error) {
+	ctx, cancel := context.WithTimeout(ctx, check.timeout(DefaultHttpTimeout))
+	defer cancel()
+
+	d := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: check.ConnectTimeout},
+		Config:    cfg,
+	}
+
+	started := time.Now()
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	response := &content_checkers.Response{
+		FinalURL:   check.Url,
+		TLS:        &state,
+		ServerName: cfg.ServerName,
+		Roots:      cfg.RootCAs,
+		Timings:    content_checkers.Timings{Total: time.Since(started)},
+	}
+
+	checks := check.ContentChecks
+	if len(checks) == 0 {
+		checks = content_checkers.DefaultTLSChecks()
+	}
+
+	return &result.Results{
+		Results:           runChecks(checks, response, nil),
+		Timings:           response.Timings,
+		CertificateExpiry: content_checkers.CertificateExpiry(&state),
+	}, nil
+}
+
+// tlsAddress returns the address to dial and the expected server name for
+// a url like "https://example.com" or "example.com:8443", port 443 if there
+// is none.
+func tlsAddress(target string) (string, string, error) {
+	host := target
+	if strings.Contains(target, "://") {
+		u, err := url.Parse(target)
+		if err != nil {
+			return "", "", err
+		}
+		host = u.Host
+	}
+	if host == "" {
+		return "", "", fmt.Errorf("missing host in '%s'", target)
+	}
+
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname, port = strings.Trim(host, "[]"), "443"
+	}
+
+	return net.JoinHostPort(hostname, port), hostname, nil
+}
+
+func (tm *TlsMonitor) Type() string {
+	return "TlsMonitor"
+}
@@ -0,0 +1,100 @@
+package monitors_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/monitors"
+)
+
+func TestTlsMonitor_Check(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	ch := monitors.Monitor{
+		Name: "tls",
+		Url:  ts.URL,
+		Type: monitors.TlsMonitorType,
+	}
+	tm := monitors.TlsMonitor{}
+	res, err := tm.Check(context.Background(), ch)
+	if err != nil {
+		t.Fatalf("got err %v, expected nil", err)
+	}
+
+	// The test server's certificate is valid for 127.0.0.1 and long lived,
+	// but self signed.
+	expected := map[string]bool{
+		"TLSExpiryChecker":   true,
+		"TLSHostnameChecker": true,
+		"TLSChainChecker":    false,
+		"TLSVersionChecker":  true,
+	}
+	if len(res.Results) != len(expected) {
+		t.Fatalf("got %d results, expected the %d default checks", len(res.Results), len(expected))
+	}
+	for _, r := range res.Results {
+		if r.Result != expected[r.ContentChecker.Type()] {
+			t.Errorf("%s: got %t (err: %v), expected %t", r.ContentChecker, r.Result, r.Err, expected[r.ContentChecker.Type()])
+		}
+	}
+
+	if time.Until(res.CertificateExpiry) < 365*24*time.Hour {
+		t.Errorf("got certificate expiry %s, expected the test server's certificate", res.CertificateExpiry)
+	}
+}
+
+func TestTlsMonitor_CheckConfiguredChecks(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	expiry, _ := content_checkers.NewTLSExpiryChecker("expiry", "30d")
+	ch := monitors.Monitor{
+		Name: "tls",
+		// host:port without a scheme
+		Url:  ts.Listener.Addr().String(),
+		Type: monitors.TlsMonitorType,
+		ContentChecks: []content_checkers.ContentCheckerHolder{
+			{ContentChecker: expiry},
+		},
+	}
+	tm := monitors.TlsMonitor{}
+	res, err := tm.Check(context.Background(), ch)
+	if err != nil {
+		t.Fatalf("got err %v, expected nil", err)
+	}
+
+	if len(res.Results) != 1 || !res.AllTrue() {
+		t.Errorf("got %+v, expected only the configured expiry check to pass", res.Results)
+	}
+}
+
+func TestTlsMonitor_CheckWeakVersion(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11}
+	ts.StartTLS()
+	defer ts.Close()
+
+	version, _ := content_checkers.NewTLSVersionChecker("version", "")
+	ch := monitors.Monitor{
+		Name: "tls",
+		Url:  ts.URL,
+		Type: monitors.TlsMonitorType,
+		ContentChecks: []content_checkers.ContentCheckerHolder{
+			{ContentChecker: version},
+		},
+	}
+	tm := monitors.TlsMonitor{}
+	res, err := tm.Check(context.Background(), ch)
+	if err != nil {
+		t.Fatalf("got err %v, expected the weak version to be reported by the check", err)
+	}
+
+	if len(res.Results) != 1 || res.Results[0].Result {
+		t.Errorf("got %+v, expected the version check to fail", res.Results)
+	}
+}
@@ -0,0 +1,74 @@
+package monitors
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+	"website-monitor/content_checkers"
+)
+
+// timingTrace collects the timings of requests through httptrace. Its
+// callbacks can be called from other goroutines, for example when dialing
+// several addresses at once.
+type timingTrace struct {
+	mu            sync.Mutex
+	wroteRequest  time.Time
+	dnsStart      time.Time
+	tlsStart      time.Time
+	connectStarts map[string]time.Time
+	timings       content_checkers.Timings
+}
+
+func newTimingTrace() *timingTrace {
+	return &timingTrace{
+		connectStarts: make(map[string]time.Time),
+	}
+}
+
+func (tt *timingTrace) record(f func(now time.Time)) {
+	now := time.Now()
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	f(now)
+}
+
+func (tt *timingTrace) ClientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			tt.record(func(now time.Time) { tt.dnsStart = now })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			tt.record(func(now time.Time) { tt.timings.DNS += now.Sub(tt.dnsStart) })
+		},
+		ConnectStart: func(network, addr string) {
+			tt.record(func(now time.Time) { tt.connectStarts[network+addr] = now })
+		},
+		ConnectDone: func(network, addr string, err error) {
+			tt.record(func(now time.Time) {
+				if err == nil {
+					tt.timings.Connect += now.Sub(tt.connectStarts[network+addr])
+				}
+			})
+		},
+		TLSHandshakeStart: func() {
+			tt.record(func(now time.Time) { tt.tlsStart = now })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			tt.record(func(now time.Time) { tt.timings.TLS += now.Sub(tt.tlsStart) })
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			tt.record(func(now time.Time) { tt.wroteRequest = now })
+		},
+		GotFirstResponseByte: func() {
+			tt.record(func(now time.Time) { tt.timings.TTFB += now.Sub(tt.wroteRequest) })
+		},
+	}
+}
+
+func (tt *timingTrace) Timings() content_checkers.Timings {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	return tt.timings
+}
@@ -0,0 +1,289 @@
+package monitors
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"website-monitor/content_checkers"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/jsonquery"
+	"github.com/go-rod/rod"
+)
+
+// Step is one request of a transaction monitor.
+type Step struct {
+	Name               string                       `yaml:"name"`
+	Url                string                       `yaml:"url"`
+	Headers            map[string]string            `yaml:"headers"`
+	ExpectedStatusCode content_checkers.StatusCodes `yaml:"expected_status_code"`
+
+	Method   string            `yaml:"method"`
+	Body     string            `yaml:"body"`
+	BodyFile string            `yaml:"body_file"`
+	Form     map[string]string `yaml:"form"`
+	JsonBody interface{}       `yaml:"json_body"`
+
+	Captures      []Capture                               `yaml:"capture"`
+	ContentChecks []content_checkers.ContentCheckerHolder `yaml:"checks"`
+}
+
+type CaptureType string
+
+const (
+	RegexCapture    CaptureType = "regex"
+	JsonPathCapture CaptureType = "json_path"
+	XPathCapture    CaptureType = "xpath"
+	HeaderCapture   CaptureType = "header"
+)
+
+// Capture extracts a value from a step's response into a variable, which
+// later steps use as ${name}.
+type Capture struct {
+	Name string      `yaml:"name"`
+	Type CaptureType `yaml:"type"`
+	// Path is the JSONPath or XPath expression or the header name.
+	Path string `yaml:"path"`
+	// Value is a regex, the body for regex captures or what was found at
+	// Path otherwise. The first group is captured if it has one, the whole
+	// match otherwise.
+	Value string `yaml:"value"`
+}
+
+var variableRegex = regexp.MustCompile(`\$\{(\w+)\}`)
+var variableNameRegex = regexp.MustCompile(`^\w+$`)
+
+func (s *Step) validate() error {
+	if s.Url == "" {
+		return fmt.Errorf("step '%s' has no url", s.Name)
+	}
+	if err := s.monitor(nil).Validate(); err != nil {
+		return err
+	}
+
+	for _, c := range s.Captures {
+		if !variableNameRegex.MatchString(c.Name) {
+			return fmt.Errorf("step '%s' has invalid capture name '%s'", s.Name, c.Name)
+		}
+		switch c.Type {
+		case "", RegexCapture:
+			if c.Value == "" {
+				return fmt.Errorf("regex capture '%s' needs a value", c.Name)
+			}
+		case JsonPathCapture, XPathCapture, HeaderCapture:
+			if c.Path == "" {
+				return fmt.Errorf("%s capture '%s' needs a path", c.Type, c.Name)
+			}
+		default:
+			return fmt.Errorf("unknown capture type '%s', expected regex, json_path, xpath or header", c.Type)
+		}
+		if _, err := regexp.Compile(c.Value); err != nil {
+			return fmt.Errorf("capture '%s' has invalid regex: %v", c.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// monitor returns the request of the step as a monitor, so it's built like
+// the request of a http monitor. Variables are replaced if vars isn't nil.
+func (s *Step) monitor(vars map[string]string) *Monitor {
+	m := &Monitor{
+		Name:     s.Name,
+		Url:      s.Url,
+		Headers:  s.Headers,
+		Method:   s.Method,
+		Body:     s.Body,
+		BodyFile: s.BodyFile,
+		Form:     s.Form,
+		JsonBody: s.JsonBody,
+	}
+	if vars == nil {
+		return m
+	}
+
+	m.Url = expand(m.Url, vars)
+	m.Body = expand(m.Body, vars)
+	m.Headers = expandMap(m.Headers, vars)
+	m.Form = expandMap(m.Form, vars)
+	m.JsonBody = expandJson(m.JsonBody, vars)
+
+	return m
+}
+
+// expand replaces ${name} with the variable, leaving unknown variables as
+// they are.
+func expand(s string, vars map[string]string) string {
+	return variableRegex.ReplaceAllStringFunc(s, func(v string) string {
+		if value, ok := vars[v[2:len(v)-1]]; ok {
+			return value
+		}
+		return v
+	})
+}
+
+func expandMap(m map[string]string, vars map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	expanded := make(map[string]string, len(m))
+	for k, v := range m {
+		expanded[k] = expand(v, vars)
+	}
+
+	return expanded
+}
+
+// expandJson replaces variables in the strings of a decoded yaml value.
+func expandJson(v interface{}, vars map[string]string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return expand(v, vars)
+	case map[string]interface{}:
+		expanded := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			expanded[k] = expandJson(e, vars)
+		}
+		return expanded
+	case []interface{}:
+		expanded := make([]interface{}, len(v))
+		for i, e := range v {
+			expanded[i] = expandJson(e, vars)
+		}
+		return expanded
+	default:
+		return v
+	}
+}
+
+// resolve returns the url of a step relative to base, the monitor's url.
+func resolve(base, ref string) (string, error) {
+	if base == "" {
+		return ref, nil
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return b.ResolveReference(r).String(), nil
+}
+
+// capture returns the value the capture extracts from the response.
+func (c *Capture) capture(header http.Header, body []byte) (string, error) {
+	var found string
+	switch c.Type {
+	case "", RegexCapture:
+		found = string(body)
+	case JsonPathCapture:
+		doc, err := jsonquery.Parse(bytes.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		node, err := jsonquery.Query(doc, c.Path)
+		if err != nil {
+			return "", err
+		}
+		if node == nil {
+			return "", fmt.Errorf("nothing found at '%s'", c.Path)
+		}
+		found = node.InnerText()
+	case XPathCapture:
+		doc, err := htmlquery.Parse(bytes.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		node, err := htmlquery.Query(doc, c.Path)
+		if err != nil {
+			return "", err
+		}
+		if node == nil {
+			return "", fmt.Errorf("nothing found at '%s'", c.Path)
+		}
+		found = htmlquery.InnerText(node)
+	case HeaderCapture:
+		values, ok := header[http.CanonicalHeaderKey(c.Path)]
+		if !ok {
+			return "", fmt.Errorf("no header '%s'", c.Path)
+		}
+		found = values[0]
+	}
+
+	if c.Value == "" {
+		return found, nil
+	}
+	match := regexp.MustCompile(c.Value).FindStringSubmatch(found)
+	switch {
+	case match == nil:
+		return "", fmt.Errorf("no match for '%s'", c.Value)
+	case len(match) > 1:
+		return match[1], nil
+	default:
+		return match[0], nil
+	}
+}
+
+// Equal reports whether both steps have the same config.
+func (s Step) Equal(y Step) bool {
+	if s.Name != y.Name || s.Url != y.Url || s.Method != y.Method || s.Body != y.Body || s.BodyFile != y.BodyFile {
+		return false
+	}
+	if !reflect.DeepEqual(s.Headers, y.Headers) || !reflect.DeepEqual(s.ExpectedStatusCode, y.ExpectedStatusCode) {
+		return false
+	}
+	if !reflect.DeepEqual(s.Form, y.Form) || !reflect.DeepEqual(s.JsonBody, y.JsonBody) || !reflect.DeepEqual(s.Captures, y.Captures) {
+		return false
+	}
+
+	if len(s.ContentChecks) != len(y.ContentChecks) {
+		return false
+	}
+	for i := range s.ContentChecks {
+		if !s.ContentChecks[i].Equal(y.ContentChecks[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// stepChecker names the step a result is for.
+type stepChecker struct {
+	content_checkers.ContentChecker
+	step string
+}
+
+func (s stepChecker) String() string {
+	return fmt.Sprintf("%s: %s", s.step, s.ContentChecker)
+}
+
+// captureChecker is the failed result of a capture, which has no checker of
+// its own.
+type captureChecker struct {
+	name string
+}
+
+func (c captureChecker) String() string {
+	return fmt.Sprintf("capture '%s'", c.name)
+}
+
+func (c captureChecker) Check(r io.Reader) (bool, error) {
+	return false, errors.New("captures are made by transaction monitors")
+}
+
+func (c captureChecker) CheckRender(p *rod.Page) (bool, error) {
+	return false, errors.New("captures are made by transaction monitors")
+}
+
+func (c captureChecker) Type() string {
+	return "CaptureChecker"
+}
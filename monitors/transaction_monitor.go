@@ -0,0 +1,183 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptrace"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/result"
+)
+
+// TransactionMonitor makes the requests of the monitor's steps in order,
+// sharing cookies, and passing captured values on to later steps.
+type TransactionMonitor struct{}
+
+func (tm *TransactionMonitor) Check(ctx context.Context, check Monitor) (*result.Results, error) {
+	if len(check.Steps) == 0 {
+		return nil, fmt.Errorf("transaction monitor '%s' has no steps", check.Name)
+	}
+
+	var results *result.Results
+	attempts, err := check.retry(ctx, func(last bool) (bool, error) {
+		var retryable bool
+		var err error
+		results, retryable, err = tm.attempt(ctx, check, last)
+		return retryable, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	results.Attempts = attempts
+
+	return results, nil
+}
+
+// attempt runs the whole transaction with a new cookie jar. It stops at the
+// first step with an unexpected status code or a value which couldn't be
+// captured, the steps after it depend on it. Timings are those of all steps together.
+func (tm *TransactionMonitor) attempt(ctx context.Context, check Monitor, last bool) (*result.Results, bool, error) {
+	hc, err := check.httpClient()
+	if err != nil {
+		return nil, false, err
+	}
+	if hc.Jar, err = cookiejar.New(nil); err != nil {
+		return nil, false, err
+	}
+
+	results := &result.Results{}
+	vars := make(map[string]string)
+	for i, step := range check.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i+1)
+		}
+
+		stepResults, retryable, err := tm.step(ctx, hc, check, step, vars, last)
+		if err != nil {
+			return nil, retryable, fmt.Errorf("%s: %v", name, err)
+		}
+
+		failed := false
+		for _, r := range stepResults.Results {
+			r.ContentChecker = stepChecker{ContentChecker: r.ContentChecker, step: name}
+			results.Results = append(results.Results, r)
+			failed = failed || (r.Required && !r.Result)
+		}
+		results.Timings.Total += stepResults.Timings.Total
+		if results.CertificateExpiry.IsZero() {
+			results.CertificateExpiry = stepResults.CertificateExpiry
+		}
+
+		if failed {
+			break
+		}
+	}
+
+	return results, false, nil
+}
+
+// step makes the request of a step and runs its checks, then captures the
+// values for the next steps into vars.
+func (tm *TransactionMonitor) step(ctx context.Context, hc *http.Client, check Monitor, step Step, vars map[string]string, last bool) (*result.Results, bool, error) {
+	sm := step.monitor(vars)
+	var err error
+	if sm.Url, err = resolve(check.Url, sm.Url); err != nil {
+		return nil, false, err
+	}
+	// Headers of the monitor are sent in every step, unless the step has
+	// its own.
+	headers := make(map[string]string)
+	for k, v := range check.Headers {
+		headers[http.CanonicalHeaderKey(k)] = v
+	}
+	for k, v := range sm.Headers {
+		headers[http.CanonicalHeaderKey(k)] = v
+	}
+	sm.Headers = headers
+
+	req, err := sm.NewRequest(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var authorization string
+	if check.Auth != nil {
+		authorization, err = check.Auth.Authorization(ctx, hc)
+		if err != nil {
+			return nil, true, err
+		}
+		req.Header.Set("Authorization", authorization)
+	}
+
+	trace := newTimingTrace()
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.ClientTrace()))
+	started := time.Now()
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized && authorization != "" {
+		check.Auth.Invalidate(authorization)
+	}
+
+	if !last && check.shouldRetryStatus(resp.StatusCode) {
+		return nil, true, fmt.Errorf("retryable statuscode: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, err
+	}
+	timings := trace.Timings()
+	timings.Total = time.Since(started)
+
+	expected := step.ExpectedStatusCode
+	if expected == nil {
+		expected = check.ExpectedStatusCode
+	}
+	statusChecker := content_checkers.NewStatusCodeChecker(expected, resp.StatusCode)
+	statusOk, _ := statusChecker.Check(nil)
+	response := newResponse(resp)
+	response.Timings = timings
+	results := &result.Results{
+		Timings:           timings,
+		CertificateExpiry: content_checkers.CertificateExpiry(resp.TLS),
+		Results: []result.Result{
+			{
+				ContentChecker: statusChecker,
+				Result:         statusOk,
+				Required:       true,
+			},
+		},
+	}
+	results.Results = append(results.Results, runChecks(step.ContentChecks, response, body)...)
+	if !statusOk {
+		return results, false, nil
+	}
+
+	// A missing value means the page isn't what the next steps expect, which
+	// fails the step like an unexpected status code.
+	for _, c := range step.Captures {
+		value, err := c.capture(resp.Header, body)
+		if err != nil {
+			results.Results = append(results.Results, result.Result{
+				ContentChecker: captureChecker{name: c.Name},
+				Err:            err,
+				Required:       true,
+			})
+			continue
+		}
+		vars[c.Name] = value
+	}
+
+	return results, false, nil
+}
+
+func (tm *TransactionMonitor) Type() string {
+	return "TransactionMonitor"
+}
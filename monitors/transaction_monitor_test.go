@@ -0,0 +1,171 @@
+package monitors_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"website-monitor/content_checkers"
+	"website-monitor/monitors"
+)
+
+// loginServer needs the csrf token from the login page to log in, and the
+// session cookie and user id from the api for the dashboard.
+func loginServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = fmt.Fprintln(w, `<html><form><input name="csrf" value="token123"></form></html>`)
+			return
+		}
+		if r.FormValue("csrf") != "token123" || r.FormValue("user") != "monitor" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1", Path: "/"})
+		http.Redirect(w, r, "/api/user", http.StatusFound)
+	})
+	mux.HandleFunc("/api/user", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprintln(w, `{"user": {"id": 42}}`)
+	})
+	mux.HandleFunc("/dashboard", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err != nil || r.Header.Get("X-CSRF-Token") != "token123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "Welcome user %s\n", r.URL.Query().Get("user"))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func loginSteps(csrf string) []monitors.Step {
+	return []monitors.Step{
+		{
+			Name: "login page",
+			Url:  "/login",
+			Captures: []monitors.Capture{
+				{Name: "csrf", Type: monitors.XPathCapture, Path: "//input[@name='csrf']/@value"},
+			},
+		},
+		{
+			Name: "login",
+			Url:  "/login",
+			Form: map[string]string{"user": "monitor", "csrf": csrf},
+			Captures: []monitors.Capture{
+				{Name: "user_id", Type: monitors.JsonPathCapture, Path: "user/id"},
+			},
+		},
+		{
+			Name:    "dashboard",
+			Url:     "/dashboard?user=${user_id}",
+			Headers: map[string]string{"X-CSRF-Token": "${csrf}"},
+			ContentChecks: []content_checkers.ContentCheckerHolder{
+				{ContentChecker: content_checkers.NewRegexChecker("welcome", "Welcome user 42", true)},
+			},
+		},
+	}
+}
+
+func TestTransactionMonitor_Check(t *testing.T) {
+	ts := loginServer()
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		steps    []monitors.Step
+		expected []bool
+	}{
+		{"logged in", loginSteps("${csrf}"), []bool{true, true, true, true}},
+		// stops at the failed login
+		{"wrong csrf token", loginSteps("wrong"), []bool{true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := monitors.Monitor{
+				Name:               "transaction",
+				Url:                ts.URL,
+				Type:               monitors.TransactionMonitorType,
+				ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+				Steps:              tt.steps,
+			}
+			tm := monitors.TransactionMonitor{}
+			res, err := tm.Check(context.Background(), ch)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			if len(res.Results) != len(tt.expected) {
+				t.Fatalf("got %d results, expected %d", len(res.Results), len(tt.expected))
+			}
+			for i, r := range res.Results {
+				if r.Result != tt.expected[i] {
+					t.Errorf("%s: got %t (err: %v), expected %t", r.ContentChecker, r.Result, r.Err, tt.expected[i])
+				}
+			}
+			if res.Timings.Total <= 0 {
+				t.Errorf("got total time %s, expected the time of all steps", res.Timings.Total)
+			}
+		})
+	}
+}
+
+func TestTransactionMonitor_CheckCaptureError(t *testing.T) {
+	ts := loginServer()
+	defer ts.Close()
+
+	steps := loginSteps("${csrf}")
+	steps[0].Captures[0] = monitors.Capture{Name: "csrf", Value: `name="token" value="(\w+)"`}
+	ch := monitors.Monitor{
+		Name:  "transaction",
+		Url:   ts.URL,
+		Type:  monitors.TransactionMonitorType,
+		Steps: steps,
+	}
+	tm := monitors.TransactionMonitor{}
+	res, err := tm.Check(context.Background(), ch)
+	if err != nil {
+		t.Fatalf("got err %v, expected the missing csrf token as a result", err)
+	}
+
+	// stops at the failed capture
+	if len(res.Results) != 2 {
+		t.Fatalf("got %d results, expected the status and the capture of the first step", len(res.Results))
+	}
+	capture := res.Results[1]
+	if capture.Result || capture.Err == nil || !capture.Required {
+		t.Errorf("%s: got %t (err: %v, required: %t), expected a required failure", capture.ContentChecker, capture.Result, capture.Err, capture.Required)
+	}
+	if res.SomeTrue() {
+		t.Errorf("expected the failed capture to fail the monitor")
+	}
+}
+
+func TestMonitor_ValidateSteps(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []monitors.Step
+		err   bool
+	}{
+		{"valid", loginSteps("${csrf}"), false},
+		{"no steps", nil, true},
+		{"no url", []monitors.Step{{Name: "step"}}, true},
+		{"two bodies", []monitors.Step{{Url: "/", Body: "body", Form: map[string]string{"a": "b"}}}, true},
+		{"invalid capture name", []monitors.Step{{Url: "/", Captures: []monitors.Capture{{Name: "a-b", Value: "x"}}}}, true},
+		{"invalid regex", []monitors.Step{{Url: "/", Captures: []monitors.Capture{{Name: "a", Value: "("}}}}, true},
+		{"json_path without path", []monitors.Step{{Url: "/", Captures: []monitors.Capture{{Name: "a", Type: monitors.JsonPathCapture}}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := monitors.Monitor{Name: "transaction", Type: monitors.TransactionMonitorType, Steps: tt.steps}
+			if err := m.Validate(); (err != nil) != tt.err {
+				t.Errorf("got err %v, expected err %t", err, tt.err)
+			}
+		})
+	}
+}
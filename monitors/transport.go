@@ -0,0 +1,182 @@
+package monitors
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// DirectProxy as proxy connects directly, ignoring the proxy environment
+// variables.
+const DirectProxy = "direct"
+
+// TLSConfig configures the TLS connections of http and tls monitors.
+type TLSConfig struct {
+	// CAFile is a PEM file with the certificates to verify servers with,
+	// instead of the system roots.
+	CAFile string `yaml:"ca_file"`
+	// CertFile and KeyFile are a client certificate for mTLS.
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	// ServerName overrides the name the certificate is verified for, which
+	// is the host of the url by default.
+	ServerName string `yaml:"server_name"`
+}
+
+func (t *TLSConfig) validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("tls needs both cert_file and key_file for a client certificate")
+	}
+
+	return nil
+}
+
+// load reads the files and returns the config for crypto/tls.
+func (t *TLSConfig) load() (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		ServerName:         t.ServerName,
+	}
+
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading ca_file: %v", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca_file %s", t.CAFile)
+		}
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// modTimes returns the modification times of the files, so changed files
+// can be read again.
+func (t *TLSConfig) modTimes() [3]time.Time {
+	var times [3]time.Time
+	for i, name := range []string{t.CAFile, t.CertFile, t.KeyFile} {
+		if name == "" {
+			continue
+		}
+		if fi, err := os.Stat(name); err == nil {
+			times[i] = fi.ModTime()
+		}
+	}
+
+	return times
+}
+
+func validateProxy(proxy string) error {
+	if proxy == "" || proxy == DirectProxy {
+		return nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid proxy '%s', expected e.g. http://proxy:3128 or socks5://proxy:1080", proxy)
+	}
+
+	return nil
+}
+
+// transportKey is everything a transport is built from.
+type transportKey struct {
+	tls            TLSConfig
+	proxy          string
+	connectTimeout time.Duration
+}
+
+type cachedTransport struct {
+	transport *http.Transport
+	modTimes  [3]time.Time
+}
+
+// transports are shared by all monitors with the same settings, so
+// connections are reused between checks.
+var transports = struct {
+	mu    sync.Mutex
+	cache map[transportKey]*cachedTransport
+}{
+	cache: make(map[transportKey]*cachedTransport),
+}
+
+// transport returns the shared transport for the monitor's settings, or nil
+// for http.DefaultTransport when it has none.
+func (c *Monitor) transport() (*http.Transport, error) {
+	key := transportKey{proxy: c.Proxy, connectTimeout: c.ConnectTimeout}
+	if c.TLS != nil {
+		key.tls = *c.TLS
+	}
+	if key == (transportKey{}) {
+		return nil, nil
+	}
+	modTimes := key.tls.modTimes()
+
+	transports.mu.Lock()
+	defer transports.mu.Unlock()
+
+	cached, ok := transports.cache[key]
+	if ok && cached.modTimes == modTimes {
+		return cached.transport, nil
+	}
+
+	t, err := newTransport(key)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		cached.transport.CloseIdleConnections()
+	}
+	transports.cache[key] = &cachedTransport{transport: t, modTimes: modTimes}
+
+	return t, nil
+}
+
+func newTransport(key transportKey) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if key.connectTimeout > 0 {
+		t.DialContext = (&net.Dialer{Timeout: key.connectTimeout}).DialContext
+		t.TLSHandshakeTimeout = key.connectTimeout
+	}
+
+	if key.tls != (TLSConfig{}) {
+		cfg, err := key.tls.load()
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig = cfg
+	}
+
+	switch key.proxy {
+	case "":
+	case DirectProxy:
+		t.Proxy = nil
+	default:
+		u, err := url.Parse(key.proxy)
+		if err != nil {
+			return nil, err
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,137 @@
+package monitors_test
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"website-monitor/content_checkers"
+	"website-monitor/monitors"
+)
+
+func writeCA(t *testing.T, ts *httptest.Server) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := ioutil.WriteFile(name, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return name
+}
+
+func TestHttpMonitor_CheckTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	chain := content_checkers.NewTLSChainChecker("chain")
+	tests := []struct {
+		name     string
+		tls      *monitors.TLSConfig
+		err      bool
+		expected bool
+	}{
+		{"untrusted", nil, true, false},
+		{"ca file", &monitors.TLSConfig{CAFile: writeCA(t, ts)}, false, true},
+		// the chain check still fails for a server which isn't trusted
+		{"insecure", &monitors.TLSConfig{InsecureSkipVerify: true}, false, false},
+		{"missing ca file", &monitors.TLSConfig{CAFile: "missing.pem"}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := monitors.Monitor{
+				Name:               "tls",
+				Url:                ts.URL,
+				ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+				TLS:                tt.tls,
+				ContentChecks:      []content_checkers.ContentCheckerHolder{{ContentChecker: chain}},
+			}
+			hm := monitors.HttpMonitor{}
+			res, err := hm.Check(context.Background(), ch)
+			if (err != nil) != tt.err {
+				t.Fatalf("got err %v, expected err %t", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if got := res.Results[1].Result; got != tt.expected {
+				t.Errorf("got chain check %t (err: %v), expected %t", got, res.Results[1].Err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTlsMonitor_CheckCAFile(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	ch := monitors.Monitor{
+		Name: "tls",
+		Url:  ts.URL,
+		Type: monitors.TlsMonitorType,
+		TLS:  &monitors.TLSConfig{CAFile: writeCA(t, ts)},
+		ContentChecks: []content_checkers.ContentCheckerHolder{
+			{ContentChecker: content_checkers.NewTLSChainChecker("chain")},
+		},
+	}
+	tm := monitors.TlsMonitor{}
+	res, err := tm.Check(context.Background(), ch)
+	if err != nil {
+		t.Fatalf("got err %v, expected nil", err)
+	}
+	if !res.Results[0].Result {
+		t.Errorf("got chain check false (err: %v), expected the ca file to be trusted", res.Results[0].Err)
+	}
+}
+
+func TestHttpMonitor_CheckProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a proxy gets the absolute url in the request line
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	ch := monitors.Monitor{
+		Name:               "proxy",
+		Url:                "http://monitored.example/health",
+		ExpectedStatusCode: content_checkers.NewStatusCodes(200),
+		Proxy:              proxy.URL,
+	}
+	hm := monitors.HttpMonitor{}
+	res, err := hm.Check(context.Background(), ch)
+	if err != nil {
+		t.Fatalf("got err %v, expected nil", err)
+	}
+	if !res.Results[0].Result {
+		t.Errorf("got status check false, expected true")
+	}
+	if proxied != ch.Url {
+		t.Errorf("got proxied url '%s', expected '%s'", proxied, ch.Url)
+	}
+}
+
+func TestMonitor_ValidateConnection(t *testing.T) {
+	tests := []struct {
+		name string
+		m    monitors.Monitor
+		err  bool
+	}{
+		{"none", monitors.Monitor{}, false},
+		{"client cert", monitors.Monitor{TLS: &monitors.TLSConfig{CertFile: "c.pem", KeyFile: "k.pem"}}, false},
+		{"cert without key", monitors.Monitor{TLS: &monitors.TLSConfig{CertFile: "c.pem"}}, true},
+		{"proxy", monitors.Monitor{Proxy: "socks5://proxy:1080"}, false},
+		{"direct", monitors.Monitor{Proxy: monitors.DirectProxy}, false},
+		{"invalid proxy", monitors.Monitor{Proxy: "proxy"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.m.Validate(); (err != nil) != tt.err {
+				t.Errorf("got err %v, expected err %t", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package monitors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/result"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxWebSocketMessages limits how many messages are collected when only
+// collect_for is set.
+const maxWebSocketMessages = 1000
+
+type MessageMatch string
+
+const (
+	// AnyMessage passes a check if it passes for any received message.
+	AnyMessage MessageMatch = "any"
+	// AllMessages passes a check if it passes for all received messages.
+	AllMessages MessageMatch = "all"
+)
+
+// WebSocketMonitor connects to a websocket, sends the monitor's messages and
+// checks the messages it receives.
+type WebSocketMonitor struct{}
+
+func (wm *WebSocketMonitor) Check(ctx context.Context, check Monitor) (*result.Results, error) {
+	dialer, err := check.websocketDialer()
+	if err != nil {
+		return nil, err
+	}
+
+	var results *result.Results
+	attempts, err := check.retry(ctx, func(last bool) (bool, error) {
+		var err error
+		results, err = wm.attempt(ctx, check, dialer)
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	results.Attempts = attempts
+
+	return results, nil
+}
+
+// websocketDialer uses the connection settings of the monitor like a http
+// monitor would.
+func (c *Monitor) websocketDialer() (*websocket.Dialer, error) {
+	d := &websocket.Dialer{
+		NetDialContext:   (&net.Dialer{Timeout: c.ConnectTimeout}).DialContext,
+		HandshakeTimeout: c.ConnectTimeout,
+		Proxy:            http.ProxyFromEnvironment,
+	}
+
+	switch c.Proxy {
+	case "":
+	case DirectProxy:
+		d.Proxy = nil
+	default:
+		u, err := url.Parse(c.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		d.Proxy = http.ProxyURL(u)
+	}
+
+	if c.TLS != nil {
+		cfg, err := c.TLS.load()
+		if err != nil {
+			return nil, err
+		}
+		d.TLSClientConfig = cfg
+	}
+
+	return d, nil
+}
+
+// attempt collects messages until it has max_messages, collect_for has
+// passed, the server closes the connection or the timeout. Receiving no
+// messages isn't an error, the checks fail instead.
+func (wm *WebSocketMonitor) attempt(ctx context.Context, check Monitor, d *websocket.Dialer) (*result.Results, error) {
+	ctx, cancel := context.WithTimeout(ctx, check.timeout(DefaultHttpTimeout))
+	defer cancel()
+
+	header := http.Header{}
+	for k, v := range check.Headers {
+		header.Add(k, v)
+	}
+	var authorization string
+	if check.Auth != nil {
+		hc, err := check.httpClient()
+		if err != nil {
+			return nil, err
+		}
+		if authorization, err = check.Auth.Authorization(ctx, hc); err != nil {
+			return nil, err
+		}
+		header.Set("Authorization", authorization)
+	}
+
+	started := time.Now()
+	conn, resp, err := d.DialContext(ctx, check.Url, header)
+	if err != nil {
+		if resp != nil {
+			if resp.StatusCode == http.StatusUnauthorized && authorization != "" {
+				check.Auth.Invalidate(authorization)
+			}
+			return nil, fmt.Errorf("websocket handshake failed with status code %d: %v", resp.StatusCode, err)
+		}
+		return nil, err
+	}
+	defer conn.Close()
+	timings := content_checkers.Timings{Connect: time.Since(started)}
+
+	// Deadlines end reads, cancelling the context has to as well.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetReadDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	for _, m := range check.Messages {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(m)); err != nil {
+			return nil, fmt.Errorf("error sending message: %v", err)
+		}
+	}
+
+	end, _ := ctx.Deadline()
+	if check.CollectFor > 0 && time.Now().Add(check.CollectFor).Before(end) {
+		end = time.Now().Add(check.CollectFor)
+	}
+	if err := conn.SetReadDeadline(end); err != nil {
+		return nil, err
+	}
+
+	sent := time.Now()
+	var messages [][]byte
+	for len(messages) < check.maxMessages() {
+		_, m, err := conn.ReadMessage()
+		var netErr net.Error
+		switch {
+		case err == nil:
+			if len(messages) == 0 {
+				timings.TTFB = time.Since(sent)
+			}
+			messages = append(messages, m)
+			continue
+		case websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway):
+		case errors.As(err, &netErr) && netErr.Timeout():
+			// The timeout or collect_for ends collecting, a check which
+			// was cancelled has no result.
+			if ctx.Err() == context.Canceled {
+				return nil, ctx.Err()
+			}
+		default:
+			return nil, err
+		}
+		break
+	}
+	timings.Total = time.Since(started)
+
+	response := &content_checkers.Response{
+		FinalURL: check.Url,
+		Timings:  timings,
+	}
+
+	return &result.Results{
+		Results: checkMessages(check.ContentChecks, check.MessageMatch, response, messages),
+		Timings: timings,
+	}, nil
+}
+
+// maxMessages is max_messages, or with only collect_for set as many as
+// arrive in that time. Without either it's the first message.
+func (c *Monitor) maxMessages() int {
+	switch {
+	case c.MaxMessages > 0:
+		return c.MaxMessages
+	case c.CollectFor > 0:
+		return maxWebSocketMessages
+	default:
+		return 1
+	}
+}
+
+// checkMessages runs the checks over each message, a check passes if it
+// passes for any or all messages according to match. Checks which implement
+// content_checkers.ResponseChecker check the response once. No messages
+// fail all checks of messages.
+func checkMessages(checks []content_checkers.ContentCheckerHolder, match MessageMatch, response *content_checkers.Response, messages [][]byte) []result.Result {
+	var results []result.Result
+	for _, contentCheck := range checks {
+		if rc, ok := contentCheck.ContentChecker.(content_checkers.ResponseChecker); ok {
+			res, err := rc.CheckResponse(response)
+			results = append(results, result.Result{
+				ContentChecker: contentCheck.ContentChecker,
+				Result:         res,
+				Err:            err,
+			})
+			continue
+		}
+
+		var res bool
+		var err error
+		for _, m := range messages {
+			res, err = contentCheck.ContentChecker.Check(bytes.NewReader(m))
+			if match == AllMessages && !res {
+				break
+			}
+			if match != AllMessages && res {
+				break
+			}
+		}
+		results = append(results, result.Result{
+			ContentChecker: contentCheck.ContentChecker,
+			Result:         res,
+			Err:            err,
+		})
+	}
+
+	return results
+}
+
+func (wm *WebSocketMonitor) Type() string {
+	return "WebSocketMonitor"
+}
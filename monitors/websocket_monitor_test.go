@@ -0,0 +1,139 @@
+package monitors_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"website-monitor/content_checkers"
+	"website-monitor/monitors"
+
+	"github.com/gorilla/websocket"
+)
+
+// priceServer pushes three prices after a subscribe message, and keeps the
+// connection open. It refuses connections without the token header.
+func priceServer() *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, m, err := conn.ReadMessage()
+		if err != nil || string(m) != `{"subscribe":"BTC"}` {
+			return
+		}
+		for _, price := range []string{"100", "101", "error"} {
+			msg := fmt.Sprintf(`{"symbol":"BTC","price":"%s"}`, price)
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+				return
+			}
+		}
+		time.Sleep(5 * time.Second)
+	}))
+}
+
+func TestWebSocketMonitor_Check(t *testing.T) {
+	ts := priceServer()
+	defer ts.Close()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	price := content_checkers.NewRegexChecker("price", `"price":"\d+"`, true)
+	symbol := content_checkers.NewJsonPathChecker("symbol", "symbol", "BTC", true)
+	tests := []struct {
+		name        string
+		messages    []string
+		maxMessages int
+		collectFor  time.Duration
+		match       monitors.MessageMatch
+		expected    []bool
+	}{
+		{"first message", []string{`{"subscribe":"BTC"}`}, 0, 0, "", []bool{true, true}},
+		{"any of all messages", []string{`{"subscribe":"BTC"}`}, 3, 0, monitors.AnyMessage, []bool{true, true}},
+		{"all messages", []string{`{"subscribe":"BTC"}`}, 3, 0, monitors.AllMessages, []bool{false, true}},
+		{"collect for", []string{`{"subscribe":"BTC"}`}, 0, 100 * time.Millisecond, monitors.AllMessages, []bool{false, true}},
+		{"not subscribed", nil, 0, 100 * time.Millisecond, "", []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := monitors.Monitor{
+				Name:         "websocket",
+				Url:          url,
+				Type:         monitors.WebSocketMonitorType,
+				Headers:      map[string]string{"X-Token": "secret"},
+				Timeout:      2 * time.Second,
+				Messages:     tt.messages,
+				MaxMessages:  tt.maxMessages,
+				CollectFor:   tt.collectFor,
+				MessageMatch: tt.match,
+				ContentChecks: []content_checkers.ContentCheckerHolder{
+					{ContentChecker: price},
+					{ContentChecker: symbol},
+				},
+			}
+			wm := monitors.WebSocketMonitor{}
+			started := time.Now()
+			res, err := wm.Check(context.Background(), ch)
+			if err != nil {
+				t.Fatalf("got err %v, expected nil", err)
+			}
+
+			for i, r := range res.Results {
+				if r.Result != tt.expected[i] {
+					t.Errorf("%s: got %t (err: %v), expected %t", r.ContentChecker, r.Result, r.Err, tt.expected[i])
+				}
+			}
+			if elapsed := time.Since(started); elapsed > time.Second {
+				t.Errorf("took %s, expected collecting to stop before the timeout", elapsed)
+			}
+		})
+	}
+}
+
+func TestWebSocketMonitor_CheckCancelled(t *testing.T) {
+	ts := priceServer()
+	defer ts.Close()
+
+	ch := monitors.Monitor{
+		Name:       "websocket",
+		Url:        "ws" + strings.TrimPrefix(ts.URL, "http"),
+		Type:       monitors.WebSocketMonitorType,
+		Headers:    map[string]string{"X-Token": "secret"},
+		CollectFor: time.Second,
+		ContentChecks: []content_checkers.ContentCheckerHolder{
+			{ContentChecker: content_checkers.NewRegexChecker("price", `"price"`, true)},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	wm := monitors.WebSocketMonitor{}
+	if _, err := wm.Check(ctx, ch); err != context.Canceled {
+		t.Errorf("got err %v, expected the cancelled check to have no result", err)
+	}
+}
+
+func TestWebSocketMonitor_CheckHandshakeError(t *testing.T) {
+	ts := priceServer()
+	defer ts.Close()
+
+	ch := monitors.Monitor{
+		Name: "websocket",
+		Url:  "ws" + strings.TrimPrefix(ts.URL, "http"),
+		Type: monitors.WebSocketMonitorType,
+	}
+	wm := monitors.WebSocketMonitor{}
+	_, err := wm.Check(context.Background(), ch)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("got err %v, expected the refused handshake", err)
+	}
+}
@@ -1,12 +1,13 @@
 package notifiers
 
 import (
+	"context"
 	"website-monitor/result"
 )
 
 type Notifier interface {
 	Name() string
-	Notify(name, displayUrl string, result *result.Results) error
+	Notify(ctx context.Context, name, displayUrl string, result *result.Results) error
 }
 
 type NotifierHolder struct {
@@ -48,3 +49,22 @@ func (n *NotifierHolder) UnmarshalYAML(unmarshal func(interface{}) error) error
 
 	return nil
 }
+
+// Equal reports whether both holders have the same notifier config.
+func (n NotifierHolder) Equal(y NotifierHolder) bool {
+	switch x := n.Notifier.(type) {
+	case *SlackNotifier:
+		yn, ok := y.Notifier.(*SlackNotifier)
+		return ok && x.Equal(yn)
+	case *PushSaferNotifier:
+		yn, ok := y.Notifier.(*PushSaferNotifier)
+		return ok && x.Equal(yn)
+	case *PostgresNotifier:
+		yn, ok := y.Notifier.(*PostgresNotifier)
+		return ok && x.Equal(yn)
+	case nil:
+		return y.Notifier == nil
+	}
+
+	return false
+}
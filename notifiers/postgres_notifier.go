@@ -19,12 +19,14 @@ type PgLog struct {
 
 type PostgresNotifier struct {
 	name   string
+	url    string
 	dbConn *pg.DB
 }
 
 func NewPostgresNotifier(name string, options map[string]string) (*PostgresNotifier, error) {
 	mn := &PostgresNotifier{
 		name: name,
+		url:  options["url"],
 	}
 
 	opts, err := pg.ParseURL(options["url"])
@@ -51,7 +53,11 @@ func (mn *PostgresNotifier) Name() string {
 	return mn.name
 }
 
-func (mn *PostgresNotifier) Notify(name, displayUrl string, result *result.Results) error {
+func (mn *PostgresNotifier) Equal(y *PostgresNotifier) bool {
+	return mn.name == y.name && mn.url == y.url
+}
+
+func (mn *PostgresNotifier) Notify(ctx context.Context, name, displayUrl string, result *result.Results) error {
 	if mn.dbConn == nil {
 		return fmt.Errorf("no postgres database connected")
 	}
@@ -63,7 +69,7 @@ func (mn *PostgresNotifier) Notify(name, displayUrl string, result *result.Resul
 		CreatedAt:     time.Now(),
 	}
 
-	_, err := mn.dbConn.Model(&pl).Insert()
+	_, err := mn.dbConn.ModelContext(ctx, &pl).Insert()
 	if err != nil {
 		return fmt.Errorf("error inserting log for %s: %v", name, err)
 	}
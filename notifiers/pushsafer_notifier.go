@@ -1,6 +1,7 @@
 package notifiers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -44,7 +45,7 @@ type PushSaferResponse struct {
 	Available int    `json:"available"`
 }
 
-func (p PushSaferNotifier) Notify(name, displayUrl string, result *result.Results) error {
+func (p PushSaferNotifier) Notify(ctx context.Context, name, displayUrl string, result *result.Results) error {
 	params := url.Values{}
 
 	for k, v := range p.options {
@@ -62,7 +63,13 @@ func (p PushSaferNotifier) Notify(name, displayUrl string, result *result.Result
 		params.Set("m", fmt.Sprintf("%s does *not* match checks!", name))
 	}
 
-	res, err := http.Post("https://www.pushsafer.com/api", "application/x-www-form-urlencoded", strings.NewReader(params.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://www.pushsafer.com/api", strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
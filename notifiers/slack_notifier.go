@@ -2,6 +2,7 @@ package notifiers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -47,7 +48,7 @@ type SlackRequestBody struct {
 	Blocks []SlackBlock `json:"blocks"`
 }
 
-func (s *SlackNotifier) Notify(name, displayUrl string, result *result.Results) error {
+func (s *SlackNotifier) Notify(ctx context.Context, name, displayUrl string, result *result.Results) error {
 	var text string
 	if result.AllTrue() {
 		text = fmt.Sprintf("<%s|%s> *matches* checks!", displayUrl, name)
@@ -75,7 +76,7 @@ func (s *SlackNotifier) Notify(name, displayUrl string, result *result.Results)
 	}
 
 	slackBody, _ := json.Marshal(body)
-	req, err := http.NewRequest(http.MethodPost, s.webhookUrl, bytes.NewBuffer(slackBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookUrl, bytes.NewBuffer(slackBody))
 	if err != nil {
 		return err
 	}
@@ -37,6 +37,37 @@ var (
 		Help: "Unix timestamp for next check.",
 	},
 		[]string{"monitor"})
+	MonitorsInMaintenance = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitors_in_maintenance",
+		Help: "Whether the monitor is currently in a maintenance window.",
+	},
+		[]string{"monitor"})
+	MonitorsResponseTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "monitors_response_time_seconds",
+		Help:    "Duration of each phase of the monitor's requests.",
+		Buckets: prometheus.DefBuckets,
+	},
+		[]string{"monitor", "phase"})
+	MonitorsTLSExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitors_tls_expiry_seconds",
+		Help: "Seconds until the earliest expiry of the monitor's certificates.",
+	},
+		[]string{"monitor"})
+	MonitorsReachabilityLoss = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitors_reachability_loss_ratio",
+		Help: "Ratio of the probes of the last reachability check which failed.",
+	},
+		[]string{"monitor"})
+	MonitorsReachabilityLatency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitors_reachability_latency_seconds",
+		Help: "Minimum, average and maximum connect time of the probes of the last reachability check.",
+	},
+		[]string{"monitor", "stat"})
+	MonitorsReachabilityProbes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "monitors_reachability_probes_total",
+		Help: "The total number of reachability probes, by whether they were received.",
+	},
+		[]string{"monitor", "received"})
 )
 
 func Init() {
@@ -48,5 +79,32 @@ func Init() {
 		MonitorsIndividualProcessed,
 		MonitorsIndividualErrored,
 		MonitorsNextCheckInfo,
+		MonitorsInMaintenance,
+		MonitorsResponseTime,
+		MonitorsTLSExpiry,
+		MonitorsReachabilityLoss,
+		MonitorsReachabilityLatency,
+		MonitorsReachabilityProbes,
 	)
 }
+
+// DeleteMonitor removes all series of the named monitor, for monitors which
+// are no longer configured. phases are the phase labels of its response
+// times and stats those of its reachability latencies.
+func DeleteMonitor(name string, phases, stats []string) {
+	LastSeenState.DeleteLabelValues(name)
+	MonitorsIndividualProcessed.DeleteLabelValues(name)
+	MonitorsIndividualErrored.DeleteLabelValues(name)
+	MonitorsNextCheckInfo.DeleteLabelValues(name)
+	MonitorsInMaintenance.DeleteLabelValues(name)
+	MonitorsTLSExpiry.DeleteLabelValues(name)
+	MonitorsReachabilityLoss.DeleteLabelValues(name)
+	for _, stat := range stats {
+		MonitorsReachabilityLatency.DeleteLabelValues(name, stat)
+	}
+	MonitorsReachabilityProbes.DeleteLabelValues(name, "true")
+	MonitorsReachabilityProbes.DeleteLabelValues(name, "false")
+	for _, phase := range phases {
+		MonitorsResponseTime.DeleteLabelValues(name, phase)
+	}
+}
@@ -1,9 +1,23 @@
 package result
 
-import "website-monitor/content_checkers"
+import (
+	"time"
+	"website-monitor/content_checkers"
+)
 
 type Results struct {
 	Results []Result
+	// Attempts is how many times the check was tried before it got these
+	// results, more than 1 if it was retried.
+	Attempts int
+	// Timings are those of the last attempt, only Total is set for
+	// rendered pages.
+	Timings content_checkers.Timings
+	// CertificateExpiry is the earliest expiry of the server's certificates,
+	// zero without TLS.
+	CertificateExpiry time.Time
+	// Probes are those of a reachability monitor, nil for other monitors.
+	Probes *content_checkers.Probes
 }
 
 func (r *Results) AllTrue() bool {
@@ -16,19 +30,32 @@ func (r *Results) AllTrue() bool {
 	return true
 }
 
+// SomeTrue reports whether all required results and at least one of the
+// others are true. Without other results, only the required ones count.
 func (r *Results) SomeTrue() bool {
+	some, optional := false, false
 	for _, result := range r.Results {
+		if result.Required {
+			if !result.Result {
+				return false
+			}
+			continue
+		}
+		optional = true
 		if result.Result {
-			return true
+			some = true
 		}
 	}
 
-	return false
+	return some || (!optional && len(r.Results) > 0)
 }
 
 type Result struct {
 	ContentChecker content_checkers.ContentChecker
 	Result         bool
 	Err            error
+	// Required results have to be true for SomeTrue as well, like the
+	// status code.
+	Required bool
 }
 
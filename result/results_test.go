@@ -133,6 +133,69 @@ func TestResults_SomeTrue(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "required false, one true",
+			results: result.Results{
+				Results: []result.Result{
+					{
+						Result:   false,
+						Required: true,
+					},
+					{
+						Result: true,
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "required true, one true, one false",
+			results: result.Results{
+				Results: []result.Result{
+					{
+						Result:   true,
+						Required: true,
+					},
+					{
+						Result: true,
+					},
+					{
+						Result: false,
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "required true, two false",
+			results: result.Results{
+				Results: []result.Result{
+					{
+						Result:   true,
+						Required: true,
+					},
+					{
+						Result: false,
+					},
+					{
+						Result: false,
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "only required, true",
+			results: result.Results{
+				Results: []result.Result{
+					{
+						Result:   true,
+						Required: true,
+					},
+				},
+			},
+			expected: true,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
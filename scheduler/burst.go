@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Burst makes a monitor check more often for a while after its state has
+// changed, to confirm the change and catch flapping.
+type Burst struct {
+	Interval time.Duration `yaml:"interval"`
+	Duration time.Duration `yaml:"duration"`
+}
+
+func (b *Burst) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type alias struct {
+		Interval string `yaml:"interval"`
+		Duration string `yaml:"duration"`
+	}
+
+	var tmp alias
+	if err := unmarshal(&tmp); err != nil {
+		return err
+	}
+
+	interval, err := time.ParseDuration(tmp.Interval)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid burst interval '%s'", tmp.Interval)
+	}
+
+	duration, err := time.ParseDuration(tmp.Duration)
+	if err != nil || duration <= 0 {
+		return fmt.Errorf("invalid burst duration '%s'", tmp.Duration)
+	}
+
+	b.Interval = interval
+	b.Duration = duration
+
+	return nil
+}
+
+// Backoff makes a monitor check less often while its checks keep failing,
+// multiplying the regular interval by Multiplier for each consecutive error
+// up to Max, or DefaultBackoffMax if it isn't set.
+type Backoff struct {
+	Multiplier float64       `yaml:"multiplier"`
+	Max        time.Duration `yaml:"max"`
+}
+
+const defaultBackoffMultiplier = 2
+
+// DefaultBackoffMax keeps monitors which error for a long time without a max
+// checked at least once a day.
+const DefaultBackoffMax = 24 * time.Hour
+
+func (b *Backoff) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type alias struct {
+		Multiplier float64 `yaml:"multiplier"`
+		Max        string  `yaml:"max"`
+	}
+
+	var tmp alias
+	if err := unmarshal(&tmp); err != nil {
+		return err
+	}
+
+	if tmp.Multiplier == 0 {
+		tmp.Multiplier = defaultBackoffMultiplier
+	}
+	if tmp.Multiplier < 1 {
+		return fmt.Errorf("invalid error_backoff multiplier %v, must be at least 1", tmp.Multiplier)
+	}
+
+	if tmp.Max != "" {
+		max, err := time.ParseDuration(tmp.Max)
+		if err != nil {
+			return fmt.Errorf("invalid error_backoff max '%s': %v", tmp.Max, err)
+		}
+		b.Max = max
+	}
+
+	b.Multiplier = tmp.Multiplier
+
+	return nil
+}
+
+// CalculateNextBurstFrom returns the next check during a burst. Bursts
+// ignore days, hours and windows since they confirm a change which was just
+// seen.
+func (s *Scheduler) CalculateNextBurstFrom(from time.Time) time.Time {
+	if s.Burst == nil {
+		return s.CalculateNextFrom(from)
+	}
+
+	return from.Add(s.Burst.Interval)
+}
+
+// CalculateNextBackoffFrom returns the next check after the given number of
+// consecutive errors.
+func (s *Scheduler) CalculateNextBackoffFrom(from time.Time, errors int) time.Time {
+	next := s.CalculateNextFrom(from)
+	if s.ErrorBackoff == nil || errors <= 0 {
+		return next
+	}
+
+	max := s.ErrorBackoff.Max
+	if max <= 0 {
+		max = DefaultBackoffMax
+	}
+
+	// The delay is capped as a float, after enough errors it's too long for
+	// a time.Duration.
+	base := next.Sub(from)
+	delay := max
+	if d := float64(base) * math.Pow(s.ErrorBackoff.Multiplier, float64(errors)); d < float64(max) {
+		delay = time.Duration(d)
+	}
+	if delay < base {
+		return next
+	}
+
+	return s.nextWithinSchedule(from.Add(delay))
+}
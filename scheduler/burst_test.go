@@ -0,0 +1,169 @@
+package scheduler_test
+
+import (
+	"gopkg.in/yaml.v3"
+	"testing"
+	"time"
+	"website-monitor/scheduler"
+)
+
+func TestScheduler_CalculateNextBackoffFrom(t *testing.T) {
+	tests := []struct {
+		name     string
+		backoff  *scheduler.Backoff
+		errors   int
+		hours    []int
+		expected time.Time
+	}{
+		{
+			name:     "no backoff configured",
+			errors:   3,
+			expected: time.Date(2021, 02, 17, 10, 01, 00, 00, time.UTC),
+		},
+		{
+			name:     "no errors",
+			backoff:  &scheduler.Backoff{Multiplier: 2},
+			expected: time.Date(2021, 02, 17, 10, 01, 00, 00, time.UTC),
+		},
+		{
+			name:     "first error",
+			backoff:  &scheduler.Backoff{Multiplier: 2},
+			errors:   1,
+			expected: time.Date(2021, 02, 17, 10, 02, 00, 00, time.UTC),
+		},
+		{
+			name:     "third error",
+			backoff:  &scheduler.Backoff{Multiplier: 2},
+			errors:   3,
+			expected: time.Date(2021, 02, 17, 10, 8, 00, 00, time.UTC),
+		},
+		{
+			name:     "capped at max",
+			backoff:  &scheduler.Backoff{Multiplier: 2, Max: 5 * time.Minute},
+			errors:   10,
+			expected: time.Date(2021, 02, 17, 10, 5, 00, 00, time.UTC),
+		},
+		{
+			name:     "many errors",
+			backoff:  &scheduler.Backoff{Multiplier: 2, Max: time.Hour},
+			errors:   40,
+			expected: time.Date(2021, 02, 17, 11, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "many errors without max",
+			backoff:  &scheduler.Backoff{Multiplier: 2},
+			errors:   40,
+			expected: time.Date(2021, 02, 18, 10, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "max below interval",
+			backoff:  &scheduler.Backoff{Multiplier: 2, Max: 30 * time.Second},
+			errors:   10,
+			expected: time.Date(2021, 02, 17, 10, 01, 00, 00, time.UTC),
+		},
+		{
+			name:     "backoff into hours",
+			backoff:  &scheduler.Backoff{Multiplier: 2, Max: 2 * time.Hour},
+			errors:   10,
+			hours:    []int{10},
+			expected: time.Date(2021, 02, 18, 10, 00, 00, 00, time.UTC),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := scheduler.NewScheduler(time.Minute, nil, test.hours, nil)
+			s.ErrorBackoff = test.backoff
+
+			from := time.Date(2021, 02, 17, 10, 00, 00, 00, time.UTC)
+			to := s.CalculateNextBackoffFrom(from, test.errors)
+			if !to.Equal(test.expected) {
+				t.Errorf("got: %s, expected: %s", to.String(), test.expected.String())
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateNextBurstFrom(t *testing.T) {
+	s := scheduler.NewScheduler(time.Hour, nil, []int{9}, nil)
+	from := time.Date(2021, 02, 17, 10, 00, 00, 00, time.UTC)
+
+	if to := s.CalculateNextBurstFrom(from); !to.Equal(time.Date(2021, 02, 18, 9, 00, 00, 00, time.UTC)) {
+		t.Errorf("without burst got: %s, expected regular schedule", to.String())
+	}
+
+	s.Burst = &scheduler.Burst{Interval: 10 * time.Second, Duration: 5 * time.Minute}
+	if to := s.CalculateNextBurstFrom(from); !to.Equal(from.Add(10 * time.Second)) {
+		t.Errorf("with burst got: %s, expected: %s", to.String(), from.Add(10*time.Second).String())
+	}
+}
+
+func TestScheduler_UnmarshalYAMLBurst(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		burst   *scheduler.Burst
+		backoff *scheduler.Backoff
+		err     bool
+	}{
+		{
+			name: "burst and backoff",
+			data: `
+interval: 300s
+burst:
+  interval: 10s
+  duration: 5m
+error_backoff:
+  multiplier: 3
+  max: 1h
+`,
+			burst:   &scheduler.Burst{Interval: 10 * time.Second, Duration: 5 * time.Minute},
+			backoff: &scheduler.Backoff{Multiplier: 3, Max: time.Hour},
+		},
+		{
+			name: "default multiplier",
+			data: `
+interval: 300s
+error_backoff:
+  max: 1h
+`,
+			backoff: &scheduler.Backoff{Multiplier: 2, Max: time.Hour},
+		},
+		{
+			name: "missing burst duration",
+			data: `
+interval: 300s
+burst:
+  interval: 10s
+`,
+			err: true,
+		},
+		{
+			name: "multiplier below one",
+			data: `
+interval: 300s
+error_backoff:
+  multiplier: 0.5
+`,
+			err: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &scheduler.Scheduler{}
+			err := yaml.Unmarshal([]byte(test.data), s)
+			if (err != nil) != test.err {
+				t.Fatalf("got err %v, expected err: %t", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+
+			expected := scheduler.NewScheduler(300*time.Second, nil, nil, nil)
+			expected.Burst = test.burst
+			expected.ErrorBackoff = test.backoff
+			if !s.Equal(expected) {
+				t.Errorf("got burst %v, backoff %v, expected %v, %v", s.Burst, s.ErrorBackoff, test.burst, test.backoff)
+			}
+		})
+	}
+}
@@ -8,6 +8,14 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// cronParser accepts the standard 5 field syntax, an optional leading
+// seconds field and descriptors such as @hourly or @every 5m.
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
 )
 
 type Days []time.Weekday
@@ -24,6 +32,34 @@ func (d Days) String() string {
 	return dayStr
 }
 
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// parseWeekday accepts a day number (0 is Sunday) or an English day name,
+// either abbreviated ("mon") or in full ("Monday").
+func parseWeekday(str string) (time.Weekday, error) {
+	str = strings.ToLower(strings.TrimSpace(str))
+	if len(str) >= 3 {
+		if wd, ok := weekdayNames[str[:3]]; ok && strings.HasPrefix(strings.ToLower(wd.String()), str) {
+			return wd, nil
+		}
+	}
+
+	di, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Weekday(di), nil
+}
+
 func (d *Days) FromString(str string) error {
 	for _, s := range strings.Split(str, ",") {
 		s = strings.Trim(s, " ")
@@ -33,26 +69,30 @@ func (d *Days) FromString(str string) error {
 
 		if strings.Contains(s, "-") {
 			fromTo := strings.Split(s, "-")
-			from, err := strconv.Atoi(fromTo[0])
+			from, err := parseWeekday(fromTo[0])
 			if err != nil {
 				return fmt.Errorf("invalid from in range '%s': %v", fromTo, err)
 			}
-			to, err := strconv.Atoi(fromTo[1])
+			to, err := parseWeekday(fromTo[1])
 			if err != nil {
 				return fmt.Errorf("invalid to in range '%s': %v", fromTo, err)
 			}
+			// Ranges like fri-mon wrap around the end of the week.
+			if to < from {
+				to += 7
+			}
 			for i := from; i <= to; i++ {
-				*d = append(*d, time.Weekday(i))
+				*d = append(*d, i%7)
 			}
 
 			continue
 		}
 
-		di, err := strconv.Atoi(s)
+		di, err := parseWeekday(s)
 		if err != nil {
 			return err
 		}
-		*d = append(*d, time.Weekday(di))
+		*d = append(*d, di)
 	}
 
 	return nil
@@ -129,6 +169,15 @@ type Scheduler struct {
 	IntervalVariationPercentage *int          `yaml:"interval_variation_percentage"`
 	Hours                       Hours         `yaml:"hours"`
 	Days                        Days          `yaml:"days"`
+	Cron                        string        `yaml:"cron"`
+	Timezone                    string        `yaml:"timezone"`
+	Windows                     Windows       `yaml:"windows"`
+	Burst                       *Burst        `yaml:"burst"`
+	ErrorBackoff                *Backoff      `yaml:"error_backoff"`
+	Stagger                     bool          `yaml:"stagger"`
+
+	cron     cron.Schedule
+	location *time.Location
 }
 
 func NewScheduler(interval time.Duration, intervalVariationPercentage *int, hours Hours, days Days) *Scheduler {
@@ -143,10 +192,21 @@ func NewScheduler(interval time.Duration, intervalVariationPercentage *int, hour
 	return s
 }
 
+// NewCronScheduler returns a Scheduler which runs on the given cron
+// expression instead of a fixed interval.
+func NewCronScheduler(expr string, intervalVariationPercentage *int, hours Hours, days Days) (*Scheduler, error) {
+	s := NewScheduler(0, intervalVariationPercentage, hours, days)
+	if err := s.SetCron(expr); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
 func NewSchedulerFromString(input string) (*Scheduler, error) {
 	parts := strings.Split(input, ";")
-	if len(parts) != 4 {
-		return nil, fmt.Errorf("need 4 parts, only %d given", len(parts))
+	if len(parts) < 4 || len(parts) > 7 {
+		return nil, fmt.Errorf("need 4 to 7 parts, %d given", len(parts))
 	}
 
 	interval, err := strconv.Atoi(parts[0])
@@ -159,8 +219,8 @@ func NewSchedulerFromString(input string) (*Scheduler, error) {
 		return nil, err
 	}
 
-	days := &Days{}
-	hours := &Hours{}
+	var days Days
+	var hours Hours
 
 	if err := days.FromString(parts[2]); err != nil {
 		return nil, err
@@ -170,12 +230,84 @@ func NewSchedulerFromString(input string) (*Scheduler, error) {
 		return nil, err
 	}
 
-	return &Scheduler{
+	s := &Scheduler{
 		Interval:                    time.Duration(interval) * time.Second,
 		IntervalVariationPercentage: &variation,
-		Days:                        *days,
-		Hours:                       *hours,
-	}, nil
+		Days:                        days,
+		Hours:                       hours,
+	}
+
+	if len(parts) > 4 {
+		if err := s.SetCron(parts[4]); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(parts) > 5 {
+		if err := s.SetTimezone(parts[5]); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(parts) > 6 {
+		if err := s.Windows.FromString(parts[6]); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// SetCron parses expr and makes the scheduler use it instead of the interval.
+// An empty expression switches back to interval scheduling.
+func (s *Scheduler) SetCron(expr string) error {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		s.Cron = ""
+		s.cron = nil
+		return nil
+	}
+
+	c, err := cronParser.Parse(expr)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression '%s': %v", expr, err)
+	}
+
+	s.Cron = expr
+	s.cron = c
+
+	return nil
+}
+
+// Location returns the time zone days and hours are evaluated in, which is
+// UTC unless a timezone has been configured.
+func (s *Scheduler) Location() *time.Location {
+	if s.location == nil {
+		return time.UTC
+	}
+
+	return s.location
+}
+
+// SetTimezone makes days and hours be evaluated in the given IANA time zone,
+// e.g. "Europe/Oslo". An empty name resets to UTC.
+func (s *Scheduler) SetTimezone(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		s.Timezone = ""
+		s.location = nil
+		return nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid timezone '%s': %v", name, err)
+	}
+
+	s.Timezone = name
+	s.location = loc
+
+	return nil
 }
 
 func (s *Scheduler) IsWithinDays(t time.Time) bool {
@@ -183,6 +315,7 @@ func (s *Scheduler) IsWithinDays(t time.Time) bool {
 		return true
 	}
 
+	t = t.In(s.Location())
 	for _, d := range s.Days {
 		if t.Weekday() == d {
 			return true
@@ -197,6 +330,7 @@ func (s *Scheduler) IsWithinHours(t time.Time) bool {
 		return true
 	}
 
+	t = t.In(s.Location())
 	for _, h := range s.Hours {
 		if t.Hour() == h {
 			return true
@@ -206,54 +340,79 @@ func (s *Scheduler) IsWithinHours(t time.Time) bool {
 	return false
 }
 
+func (s *Scheduler) IsWithinWindows(t time.Time) bool {
+	return s.Windows.Contains(t.In(s.Location()))
+}
+
 func (s *Scheduler) IsWithinSchedule(t time.Time) bool {
-	return s.IsWithinDays(t) && s.IsWithinHours(t)
+	return s.IsWithinDays(t) && s.IsWithinHours(t) && s.IsWithinWindows(t)
 }
 
-func (s *Scheduler) NextDay(from time.Time) time.Time {
-	to := from
+// isDayInSchedule reports whether some part of the day starting at t can be
+// within the schedule.
+func (s *Scheduler) isDayInSchedule(t time.Time) bool {
+	return s.IsWithinDays(t) && s.Windows.overlapsDay(t.In(s.Location()).Weekday())
+}
 
-	if len(s.Days) == 0 {
-		return time.Date(to.Year(), to.Month(), to.Day(), 00, 00, 00, 00, time.UTC)
+// isHourInSchedule reports whether some part of the hour starting at t can
+// be within the schedule.
+func (s *Scheduler) isHourInSchedule(t time.Time) bool {
+	return s.IsWithinHours(t) && s.Windows.overlapsHour(t.In(s.Location()))
+}
+
+// NextWindow returns the first start of a window after from, or from itself
+// if there are no windows.
+func (s *Scheduler) NextWindow(from time.Time) time.Time {
+	next := s.Windows.NextStart(from.In(s.Location()))
+	if next.IsZero() {
+		return from
 	}
 
-	to = to.Add(24 * time.Hour)
-	to = time.Date(to.Year(), to.Month(), to.Day(), 00, 00, 00, 00, time.UTC)
+	return next
+}
 
-	if !s.IsWithinDays(to) {
-		for {
-			to = to.Add(24 * time.Hour)
-			if s.IsWithinDays(to) {
-				break
-			}
-		}
-		to = time.Date(to.Year(), to.Month(), to.Day(), 00, 00, 00, 00, time.UTC)
+// NextDay returns the start of the first day after from which is within days.
+// Days are stepped in calendar days rather than 24 hours so days which are
+// 23 or 25 hours long because of DST are handled correctly.
+func (s *Scheduler) NextDay(from time.Time) time.Time {
+	loc := s.Location()
+	to := from.In(loc)
+	to = time.Date(to.Year(), to.Month(), to.Day()+1, 00, 00, 00, 00, loc)
+
+	for i := 0; i < 7 && !s.isDayInSchedule(to); i++ {
+		to = time.Date(to.Year(), to.Month(), to.Day()+1, 00, 00, 00, 00, loc)
 	}
 
 	return to
 }
 
 func (s *Scheduler) NextHour(from time.Time) time.Time {
-	to := from
+	to := from.In(s.Location())
 
-	if from.Hour() > s.Hours[len(s.Hours)-1] {
+	if len(s.Hours) > 0 && to.Hour() > s.Hours[len(s.Hours)-1] {
 		to = s.NextDay(to)
 	}
 
-	if !s.IsWithinHours(to) {
-		for {
+	if !s.isHourInSchedule(to) {
+		// Step in absolute hours so hours skipped or repeated by DST
+		// transitions are seen as they happen on the wall clock.
+		for i := 0; i < 24*8; i++ {
 			to = to.Add(1 * time.Hour)
-			if s.IsWithinHours(to) {
+			if s.isHourInSchedule(to) {
 				break
 			}
 		}
-		to = time.Date(to.Year(), to.Month(), to.Day(), to.Hour(), 00, 00, 00, time.UTC)
+		to = to.Add(-time.Duration(to.Minute())*time.Minute - time.Duration(to.Second())*time.Second - time.Duration(to.Nanosecond()))
 	}
 
 	return to
 }
 
 func (s *Scheduler) CalculateNextFrom(from time.Time) time.Time {
+	if s.cron != nil {
+		return s.calculateNextCronFrom(from)
+	}
+
 	incBy := int(s.Interval.Seconds())
 
 	if s.IntervalVariationPercentage != nil && *s.IntervalVariationPercentage > 0 {
@@ -267,16 +426,57 @@ func (s *Scheduler) CalculateNextFrom(from time.Time) time.Time {
 
 	to := from.Add(time.Duration(incBy) * time.Second)
 
-	if s.IsWithinSchedule(to) {
-		return to
+	return s.nextWithinSchedule(to)
+}
+
+// nextWithinSchedule returns the first time from from which is within the
+// schedule. Each step moves to where the first failing restriction could be
+// satisfied, so no time within the schedule is ever skipped.
+func (s *Scheduler) nextWithinSchedule(from time.Time) time.Time {
+	to := from
+	for i := 0; i < 100; i++ {
+		switch {
+		case !s.isDayInSchedule(to):
+			to = s.NextDay(to)
+		case !s.isHourInSchedule(to):
+			to = s.NextHour(to)
+		case !s.IsWithinWindows(to):
+			to = s.NextWindow(to)
+		default:
+			return to
+		}
 	}
 
-	if !s.IsWithinDays(to) {
-		to = s.NextDay(to)
+	return to
+}
+
+// maxCronIterations limits how many cron activations are skipped while
+// looking for one within days and hours, so a schedule which can never match
+// doesn't loop forever.
+const maxCronIterations = 100000
+
+func (s *Scheduler) calculateNextCronFrom(from time.Time) time.Time {
+	next := s.cron.Next(from.In(s.Location()))
+	for i := 0; i < maxCronIterations && !s.IsWithinSchedule(next); i++ {
+		next = s.cron.Next(next)
+	}
+
+	if s.IntervalVariationPercentage == nil || *s.IntervalVariationPercentage <= 0 {
+		return next
+	}
+
+	// Vary by a percentage of the time until the activation after next, so
+	// jitter scales with how often the expression fires.
+	gap := int(s.cron.Next(next).Sub(next).Seconds())
+	p := int(float64(gap) * (float64(*s.IntervalVariationPercentage) / 100))
+	if p <= 0 {
+		return next
 	}
 
-	if !s.IsWithinHours(to) {
-		to = s.NextHour(to)
+	rand.Seed(time.Now().UnixNano())
+	to := next.Add(time.Duration(rand.Intn(2*p+1)-p) * time.Second)
+	if !to.After(from) {
+		return next
 	}
 
 	return to
@@ -301,7 +501,7 @@ func (s *Scheduler) Equal(y *Scheduler) bool {
 		return false
 	}
 
-	if *s.IntervalVariationPercentage != *y.IntervalVariationPercentage {
+	if s.IntervalVariationPercentage != nil && *s.IntervalVariationPercentage != *y.IntervalVariationPercentage {
 		return false
 	}
 
@@ -311,6 +511,24 @@ func (s *Scheduler) Equal(y *Scheduler) bool {
 	if !reflect.DeepEqual(s.Hours, y.Hours) {
 		return false
 	}
+	if s.Cron != y.Cron {
+		return false
+	}
+	if s.Timezone != y.Timezone {
+		return false
+	}
+	if !reflect.DeepEqual(s.Windows, y.Windows) {
+		return false
+	}
+	if !reflect.DeepEqual(s.Burst, y.Burst) {
+		return false
+	}
+	if !reflect.DeepEqual(s.ErrorBackoff, y.ErrorBackoff) {
+		return false
+	}
+	if s.Stagger != y.Stagger {
+		return false
+	}
 
 	return true
 }
@@ -327,15 +545,33 @@ func (s *Scheduler) String() string {
 		s.Days,
 		s.Hours)
 
+	if s.Cron != "" || s.Timezone != "" || len(s.Windows) > 0 {
+		str += ";" + s.Cron
+	}
+
+	if s.Timezone != "" || len(s.Windows) > 0 {
+		str += ";" + s.Timezone
+	}
+
+	if len(s.Windows) > 0 {
+		str += ";" + s.Windows.String()
+	}
+
 	return str
 }
 
 func (s *Scheduler) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	type alias struct {
-		Interval                    string `yaml:"interval"`
-		IntervalVariationPercentage *int   `yaml:"interval_variation_percentage"`
-		Days                        Days   `yaml:"days"`
-		Hours                       Hours  `yaml:"hours"`
+		Interval                    string   `yaml:"interval"`
+		IntervalVariationPercentage *int     `yaml:"interval_variation_percentage"`
+		Days                        Days     `yaml:"days"`
+		Hours                       Hours    `yaml:"hours"`
+		Cron                        string   `yaml:"cron"`
+		Timezone                    string   `yaml:"timezone"`
+		Windows                     Windows  `yaml:"windows"`
+		Burst                       *Burst   `yaml:"burst"`
+		ErrorBackoff                *Backoff `yaml:"error_backoff"`
+		Stagger                     bool     `yaml:"stagger"`
 	}
 
 	var tmp alias
@@ -343,15 +579,25 @@ func (s *Scheduler) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
-	t, err := time.ParseDuration(tmp.Interval)
-	if err != nil {
-		return fmt.Errorf("failed to parse interval '%s' to time.Duration: %v", tmp.Interval, err)
+	if tmp.Interval != "" || tmp.Cron == "" {
+		t, err := time.ParseDuration(tmp.Interval)
+		if err != nil {
+			return fmt.Errorf("failed to parse interval '%s' to time.Duration: %v", tmp.Interval, err)
+		}
+		s.Interval = t
 	}
 
-	s.Interval = t
 	s.Hours = tmp.Hours
 	s.Days = tmp.Days
 	s.IntervalVariationPercentage = tmp.IntervalVariationPercentage
+	s.Windows = tmp.Windows
+	s.Burst = tmp.Burst
+	s.ErrorBackoff = tmp.ErrorBackoff
+	s.Stagger = tmp.Stagger
 
-	return nil
+	if err := s.SetTimezone(tmp.Timezone); err != nil {
+		return err
+	}
+
+	return s.SetCron(tmp.Cron)
 }
@@ -319,4 +319,291 @@ func TestHours_String(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestScheduler_CalculateNextFromCron(t *testing.T) {
+	tests := []struct {
+		name     string
+		cron     string
+		from     time.Time
+		days     []time.Weekday
+		hours    []int
+		expected time.Time
+	}{
+		{
+			name:     "every 5 minutes",
+			cron:     "*/5 * * * *",
+			from:     time.Date(2021, 02, 17, 10, 02, 13, 00, time.UTC),
+			expected: time.Date(2021, 02, 17, 10, 05, 00, 00, time.UTC),
+		},
+		{
+			name:     "fixed times of day",
+			cron:     "30 8 * * *",
+			from:     time.Date(2021, 02, 17, 10, 00, 00, 00, time.UTC),
+			expected: time.Date(2021, 02, 18, 8, 30, 00, 00, time.UTC),
+		},
+		{
+			name:     "list of hours",
+			cron:     "45 8,17 * * *",
+			from:     time.Date(2021, 02, 17, 10, 00, 00, 00, time.UTC),
+			expected: time.Date(2021, 02, 17, 17, 45, 00, 00, time.UTC),
+		},
+		{
+			name:     "with seconds",
+			cron:     "15 */5 * * * *",
+			from:     time.Date(2021, 02, 17, 10, 00, 00, 00, time.UTC),
+			expected: time.Date(2021, 02, 17, 10, 00, 15, 00, time.UTC),
+		},
+		{
+			name:     "hourly descriptor",
+			cron:     "@hourly",
+			from:     time.Date(2021, 02, 17, 10, 12, 00, 00, time.UTC),
+			expected: time.Date(2021, 02, 17, 11, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "first business day of month",
+			cron:     "0 9 1-3 * 1-5",
+			from:     time.Date(2021, 04, 30, 10, 00, 00, 00, time.UTC),
+			expected: time.Date(2021, 05, 1, 9, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "restricted by days and hours",
+			cron:     "*/5 * * * *",
+			from:     time.Date(2021, 02, 19, 17, 58, 00, 00, time.UTC),
+			days:     []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			hours:    []int{8, 9, 10, 11, 12, 13, 14, 15, 16, 17},
+			expected: time.Date(2021, 02, 22, 8, 00, 00, 00, time.UTC),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := scheduler.NewCronScheduler(test.cron, nil, test.hours, test.days)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			to := s.CalculateNextFrom(test.from)
+			if to != test.expected {
+				t.Errorf("got: %s, expected: %s", to.String(), test.expected.String())
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateNextFromCronVariation(t *testing.T) {
+	twenty := 20
+	s, err := scheduler.NewCronScheduler("*/10 * * * *", &twenty, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	from := time.Date(2021, 02, 17, 10, 01, 00, 00, time.UTC)
+	next := time.Date(2021, 02, 17, 10, 10, 00, 00, time.UTC)
+	for i := 0; i < 100; i++ {
+		to := s.CalculateNextFrom(from)
+		if to.Before(next.Add(-2*time.Minute)) || to.After(next.Add(2*time.Minute)) {
+			t.Fatalf("got: %s, expected within 2m of %s", to.String(), next.String())
+		}
+	}
+}
+
+func TestScheduler_UnmarshalYAMLCron(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "cron without interval",
+			data:     "cron: \"*/5 * * * *\"",
+			expected: "0;0;;;*/5 * * * *",
+		},
+		{
+			name:     "cron descriptor",
+			data:     "cron: \"@daily\"\ninterval_variation_percentage: 10",
+			expected: "0;10;;;@daily",
+		},
+		{
+			name: "invalid cron",
+			data: "cron: \"61 * * * *\"",
+			err:  true,
+		},
+		{
+			name: "neither cron nor interval",
+			data: "days: 1-5",
+			err:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &scheduler.Scheduler{}
+			err := yaml.Unmarshal([]byte(test.data), s)
+			if (err != nil) != test.err {
+				t.Fatalf("got err %v, expected err: %t", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+			if s.String() != test.expected {
+				t.Errorf("got '%s' expected '%s'", s.String(), test.expected)
+			}
+
+			fromString, err := scheduler.NewSchedulerFromString(s.String())
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if fromString.Cron != s.Cron {
+				t.Errorf("got cron '%s' from string, expected '%s'", fromString.Cron, s.Cron)
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateNextFromTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     time.Time
+		interval time.Duration
+		days     []time.Weekday
+		hours    []int
+		cron     string
+		expected time.Time
+	}{
+		{
+			name:     "after hours in winter",
+			from:     time.Date(2021, 02, 17, 16, 30, 00, 00, time.UTC),
+			interval: 1 * time.Hour,
+			hours:    []int{9, 10, 11, 12, 13, 14, 15, 16},
+			expected: time.Date(2021, 02, 18, 8, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "after hours in summer",
+			from:     time.Date(2021, 06, 17, 15, 30, 00, 00, time.UTC),
+			interval: 1 * time.Hour,
+			hours:    []int{9, 10, 11, 12, 13, 14, 15, 16},
+			expected: time.Date(2021, 06, 18, 7, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "within local hours, outside utc hours",
+			from:     time.Date(2021, 02, 17, 14, 30, 00, 00, time.UTC),
+			interval: 1 * time.Hour,
+			hours:    []int{9, 10, 11, 12, 13, 14, 15, 16},
+			expected: time.Date(2021, 02, 17, 15, 30, 00, 00, time.UTC),
+		},
+		{
+			name:     "local day starts before utc day",
+			from:     time.Date(2021, 02, 21, 23, 00, 00, 00, time.UTC),
+			interval: 30 * time.Minute,
+			days:     []time.Weekday{time.Monday},
+			expected: time.Date(2021, 02, 21, 23, 30, 00, 00, time.UTC),
+		},
+		{
+			name:     "spring forward, next day window",
+			from:     time.Date(2021, 03, 27, 16, 30, 00, 00, time.UTC),
+			interval: 1 * time.Hour,
+			hours:    []int{9, 10, 11, 12, 13, 14, 15, 16},
+			expected: time.Date(2021, 03, 28, 7, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "spring forward, skipped hour",
+			from:     time.Date(2021, 03, 27, 2, 00, 00, 00, time.UTC),
+			interval: 1 * time.Hour,
+			hours:    []int{2},
+			expected: time.Date(2021, 03, 29, 0, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "fall back, next day window",
+			from:     time.Date(2021, 10, 30, 15, 30, 00, 00, time.UTC),
+			interval: 1 * time.Hour,
+			hours:    []int{9, 10, 11, 12, 13, 14, 15, 16},
+			expected: time.Date(2021, 10, 31, 8, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "fall back, repeated hour",
+			from:     time.Date(2021, 10, 31, 0, 30, 00, 00, time.UTC),
+			interval: 30 * time.Minute,
+			hours:    []int{2},
+			expected: time.Date(2021, 10, 31, 1, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "fall back, after repeated hour",
+			from:     time.Date(2021, 10, 31, 1, 30, 00, 00, time.UTC),
+			interval: 30 * time.Minute,
+			hours:    []int{2},
+			expected: time.Date(2021, 11, 01, 1, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "cron in local time",
+			from:     time.Date(2021, 06, 17, 00, 00, 00, 00, time.UTC),
+			cron:     "0 9 * * *",
+			expected: time.Date(2021, 06, 17, 7, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "cron across spring forward",
+			from:     time.Date(2021, 03, 27, 12, 00, 00, 00, time.UTC),
+			cron:     "0 9 * * *",
+			expected: time.Date(2021, 03, 28, 7, 00, 00, 00, time.UTC),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := scheduler.NewScheduler(test.interval, nil, test.hours, test.days)
+			if err := s.SetTimezone("Europe/Oslo"); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if err := s.SetCron(test.cron); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			to := s.CalculateNextFrom(test.from)
+			if !to.Equal(test.expected) {
+				t.Errorf("got: %s, expected: %s", to.UTC().String(), test.expected.String())
+			}
+		})
+	}
+}
+
+func TestScheduler_SetTimezone(t *testing.T) {
+	s := scheduler.NewScheduler(0, nil, nil, nil)
+	if err := s.SetTimezone("Not/AZone"); err == nil {
+		t.Error("expected err for invalid timezone, got nil")
+	}
+	if s.Location() != time.UTC {
+		t.Errorf("got location %s, expected UTC", s.Location())
+	}
+}
+
+func TestDays_FromStringNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected scheduler.Days
+	}{
+		{
+			name:     "mon-fri",
+			data:     "mon-fri",
+			expected: scheduler.Days{1, 2, 3, 4, 5},
+		},
+		{
+			name:     "full names",
+			data:     "Monday,Wednesday",
+			expected: scheduler.Days{1, 3},
+		},
+		{
+			name:     "sat-sun wraps",
+			data:     "sat-sun",
+			expected: scheduler.Days{6, 0},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var d scheduler.Days
+			if err := d.FromString(test.data); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if !reflect.DeepEqual(d, test.expected) {
+				t.Errorf("got '%v' expected '%v'", d, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// StaggerOffset returns an offset within span derived from key. The same key
+// always gets the same offset, while different keys are spread out over the
+// span, so monitors with the same interval don't line up.
+func StaggerOffset(key string, span time.Duration) time.Duration {
+	if span <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	return time.Duration(h.Sum64() % uint64(span))
+}
+
+// CalculateNextStaggeredFrom is like CalculateNextFrom, but when Stagger is
+// set the checks for key are placed on a fixed grid of the interval, offset
+// by StaggerOffset. Variation is not applied to staggered checks.
+func (s *Scheduler) CalculateNextStaggeredFrom(from time.Time, key string) time.Time {
+	if !s.Stagger || s.cron != nil || s.Interval <= 0 {
+		return s.CalculateNextFrom(from)
+	}
+
+	offset := StaggerOffset(key, s.Interval)
+	since := from.Sub(time.Unix(0, 0).Add(offset))
+	slots := since / s.Interval
+	if since >= 0 {
+		slots++
+	}
+	to := time.Unix(0, 0).Add(offset + slots*s.Interval).In(from.Location())
+
+	return s.nextWithinSchedule(to)
+}
@@ -0,0 +1,70 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+	"website-monitor/scheduler"
+)
+
+func TestStaggerOffset(t *testing.T) {
+	span := 5 * time.Minute
+
+	if scheduler.StaggerOffset("monitor a", span) != scheduler.StaggerOffset("monitor a", span) {
+		t.Error("expected the same offset for the same key")
+	}
+
+	seen := make(map[time.Duration]bool)
+	for _, key := range []string{"monitor a", "monitor b", "monitor c", "monitor d"} {
+		offset := scheduler.StaggerOffset(key, span)
+		if offset < 0 || offset >= span {
+			t.Errorf("got offset %s for %s, expected within %s", offset, key, span)
+		}
+		seen[offset] = true
+	}
+	if len(seen) < 2 {
+		t.Error("expected different keys to be spread out")
+	}
+
+	if scheduler.StaggerOffset("monitor a", 0) != 0 {
+		t.Error("expected zero offset for zero span")
+	}
+}
+
+func TestScheduler_CalculateNextStaggeredFrom(t *testing.T) {
+	s := scheduler.NewScheduler(5*time.Minute, nil, nil, nil)
+	from := time.Date(2021, 02, 17, 10, 00, 00, 00, time.UTC)
+
+	if to := s.CalculateNextStaggeredFrom(from, "monitor a"); !to.Equal(from.Add(5 * time.Minute)) {
+		t.Errorf("without stagger got: %s, expected regular interval", to)
+	}
+
+	s.Stagger = true
+	first := s.CalculateNextStaggeredFrom(from, "monitor a")
+	if !first.After(from) || first.After(from.Add(5*time.Minute)) {
+		t.Errorf("got: %s, expected within one interval of %s", first, from)
+	}
+
+	// Checks which run a bit late stay on the same grid.
+	second := s.CalculateNextStaggeredFrom(first.Add(3*time.Second), "monitor a")
+	if !second.Equal(first.Add(5 * time.Minute)) {
+		t.Errorf("got: %s, expected: %s", second, first.Add(5*time.Minute))
+	}
+
+	for _, key := range []string{"monitor a", "monitor b"} {
+		to := s.CalculateNextStaggeredFrom(from, key)
+		if got := time.Duration(to.UnixNano()) % (5 * time.Minute); got != scheduler.StaggerOffset(key, 5*time.Minute) {
+			t.Errorf("got offset %s for %s, expected %s", got, key, scheduler.StaggerOffset(key, 5*time.Minute))
+		}
+	}
+}
+
+func TestScheduler_CalculateNextStaggeredFromHours(t *testing.T) {
+	s := scheduler.NewScheduler(5*time.Minute, nil, []int{9}, nil)
+	s.Stagger = true
+
+	from := time.Date(2021, 02, 17, 10, 00, 00, 00, time.UTC)
+	to := s.CalculateNextStaggeredFrom(from, "monitor a")
+	if !to.Equal(time.Date(2021, 02, 18, 9, 00, 00, 00, time.UTC)) {
+		t.Errorf("got: %s, expected start of next hours", to)
+	}
+}
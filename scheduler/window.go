@@ -0,0 +1,246 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is a time of day range, optionally limited to some days of the
+// week, e.g. "mon-fri 09:30-11:15". Start is inclusive and End exclusive,
+// both in minutes since midnight. A window where End is before Start spans
+// midnight and belongs to the day it starts on.
+type Window struct {
+	Days  Days
+	Start int
+	End   int
+}
+
+func (w Window) String() string {
+	times := fmt.Sprintf("%02d:%02d-%02d:%02d", w.Start/60, w.Start%60, w.End/60, w.End%60)
+	if len(w.Days) == 0 {
+		return times
+	}
+
+	return fmt.Sprintf("%s %s", w.Days, times)
+}
+
+func (w *Window) FromString(str string) error {
+	fields := strings.Fields(str)
+	if len(fields) == 0 || len(fields) > 2 {
+		return fmt.Errorf("invalid window '%s', expected '[days] HH:MM-HH:MM'", str)
+	}
+
+	w.Days = nil
+	if len(fields) == 2 {
+		if err := w.Days.FromString(fields[0]); err != nil {
+			return fmt.Errorf("invalid days in window '%s': %v", str, err)
+		}
+	}
+
+	fromTo := strings.Split(fields[len(fields)-1], "-")
+	if len(fromTo) != 2 {
+		return fmt.Errorf("invalid time range in window '%s'", str)
+	}
+
+	var err error
+	if w.Start, err = parseTimeOfDay(fromTo[0]); err != nil {
+		return fmt.Errorf("invalid start in window '%s': %v", str, err)
+	}
+	if w.End, err = parseTimeOfDay(fromTo[1]); err != nil {
+		return fmt.Errorf("invalid end in window '%s': %v", str, err)
+	}
+	if w.Start == 24*60 {
+		return fmt.Errorf("invalid start in window '%s': 24:00 is only valid as end", str)
+	}
+	if w.Start == w.End {
+		return fmt.Errorf("empty window '%s'", str)
+	}
+
+	return nil
+}
+
+func parseTimeOfDay(str string) (int, error) {
+	hourMinute := strings.Split(strings.TrimSpace(str), ":")
+	if len(hourMinute) != 2 {
+		return 0, fmt.Errorf("'%s' is not HH:MM", str)
+	}
+
+	hour, err := strconv.Atoi(hourMinute[0])
+	if err != nil {
+		return 0, err
+	}
+	minute, err := strconv.Atoi(hourMinute[1])
+	if err != nil {
+		return 0, err
+	}
+
+	if hour < 0 || hour > 24 || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("'%s' is not a valid time of day", str)
+	}
+
+	return hour*60 + minute, nil
+}
+
+func (w Window) isOnDay(d time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+
+	for _, day := range w.Days {
+		if day == d {
+			return true
+		}
+	}
+
+	return false
+}
+
+// overlapsDay reports whether any part of the window is on day d, which
+// for a window crossing midnight includes the day after one of its days.
+func (w Window) overlapsDay(d time.Weekday) bool {
+	if w.isOnDay(d) {
+		return true
+	}
+
+	return w.End <= w.Start && w.End > 0 && w.isOnDay((d+6)%7)
+}
+
+// Contains reports whether t, which should be in the schedule's location,
+// is within the window.
+func (w Window) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if w.Start < w.End {
+		return w.isOnDay(t.Weekday()) && m >= w.Start && m < w.End
+	}
+
+	return (w.isOnDay(t.Weekday()) && m >= w.Start) || (w.isOnDay((t.Weekday()+6)%7) && m < w.End)
+}
+
+// overlapsHour reports whether any part of the hour starting at t is within
+// the window.
+func (w Window) overlapsHour(t time.Time) bool {
+	hourStart := t.Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	for m := 0; m < 60; m++ {
+		if w.Contains(hourStart.Add(time.Duration(m) * time.Minute)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextStart returns the first start of the window after from.
+func (w Window) NextStart(from time.Time) time.Time {
+	for d := 0; d <= 7; d++ {
+		start := time.Date(from.Year(), from.Month(), from.Day()+d, w.Start/60, w.Start%60, 00, 00, from.Location())
+		if start.After(from) && w.isOnDay(start.Weekday()) {
+			return start
+		}
+	}
+
+	return time.Time{}
+}
+
+type Windows []Window
+
+func (ws Windows) String() string {
+	var strs []string
+	for _, w := range ws {
+		strs = append(strs, w.String())
+	}
+
+	return strings.Join(strs, "|")
+}
+
+func (ws *Windows) FromString(str string) error {
+	for _, s := range strings.Split(str, "|") {
+		s = strings.Trim(s, " ")
+		if s == "" {
+			continue
+		}
+
+		w := Window{}
+		if err := w.FromString(s); err != nil {
+			return err
+		}
+		*ws = append(*ws, w)
+	}
+
+	return nil
+}
+
+func (ws *Windows) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var strs []string
+	if err := unmarshal(&strs); err != nil {
+		return err
+	}
+
+	for _, s := range strs {
+		w := Window{}
+		if err := w.FromString(s); err != nil {
+			return err
+		}
+		*ws = append(*ws, w)
+	}
+
+	return nil
+}
+
+// Contains reports whether t is within any of the windows. No windows means
+// no restriction.
+func (ws Windows) Contains(t time.Time) bool {
+	if len(ws) == 0 {
+		return true
+	}
+
+	for _, w := range ws {
+		if w.Contains(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ws Windows) overlapsDay(d time.Weekday) bool {
+	if len(ws) == 0 {
+		return true
+	}
+
+	for _, w := range ws {
+		if w.overlapsDay(d) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ws Windows) overlapsHour(t time.Time) bool {
+	if len(ws) == 0 {
+		return true
+	}
+
+	for _, w := range ws {
+		if w.overlapsHour(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextStart returns the earliest start of any of the windows after from.
+func (ws Windows) NextStart(from time.Time) time.Time {
+	var next time.Time
+	for _, w := range ws {
+		start := w.NextStart(from)
+		if !start.IsZero() && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+
+	return next
+}
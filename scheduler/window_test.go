@@ -0,0 +1,279 @@
+package scheduler_test
+
+import (
+	"gopkg.in/yaml.v3"
+	"testing"
+	"time"
+	"website-monitor/scheduler"
+)
+
+func TestWindow_FromString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "days and times",
+			input:    "mon-fri 09:30-11:15",
+			expected: "1,2,3,4,5 09:30-11:15",
+		},
+		{
+			name:     "single day",
+			input:    "sat 10:00-14:00",
+			expected: "6 10:00-14:00",
+		},
+		{
+			name:     "numeric days",
+			input:    "1,3 08:00-09:00",
+			expected: "1,3 08:00-09:00",
+		},
+		{
+			name:     "wrapping days",
+			input:    "fri-mon 08:00-09:00",
+			expected: "5,6,0,1 08:00-09:00",
+		},
+		{
+			name:     "every day",
+			input:    "13:00-16:45",
+			expected: "13:00-16:45",
+		},
+		{
+			name:     "until midnight",
+			input:    "sun 22:00-24:00",
+			expected: "0 22:00-24:00",
+		},
+		{
+			name:  "invalid time",
+			input: "mon 09:60-10:00",
+			err:   true,
+		},
+		{
+			name:  "invalid day",
+			input: "someday 09:00-10:00",
+			err:   true,
+		},
+		{
+			name:  "empty window",
+			input: "09:00-09:00",
+			err:   true,
+		},
+		{
+			name:  "missing range",
+			input: "mon 09:00",
+			err:   true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := scheduler.Window{}
+			err := w.FromString(test.input)
+			if (err != nil) != test.err {
+				t.Fatalf("got err %v, expected err: %t", err, test.err)
+			}
+			if err == nil && w.String() != test.expected {
+				t.Errorf("got '%s', expected '%s'", w.String(), test.expected)
+			}
+		})
+	}
+}
+
+func TestWindow_Contains(t *testing.T) {
+	tests := []struct {
+		name     string
+		window   string
+		value    time.Time
+		expected bool
+	}{
+		{
+			name:     "at start",
+			window:   "wed 09:30-11:15",
+			value:    time.Date(2021, 02, 17, 9, 30, 00, 00, time.UTC),
+			expected: true,
+		},
+		{
+			name:     "before start",
+			window:   "wed 09:30-11:15",
+			value:    time.Date(2021, 02, 17, 9, 29, 59, 00, time.UTC),
+			expected: false,
+		},
+		{
+			name:     "at end",
+			window:   "wed 09:30-11:15",
+			value:    time.Date(2021, 02, 17, 11, 15, 00, 00, time.UTC),
+			expected: false,
+		},
+		{
+			name:     "other day",
+			window:   "thu 09:30-11:15",
+			value:    time.Date(2021, 02, 17, 10, 00, 00, 00, time.UTC),
+			expected: false,
+		},
+		{
+			name:     "over midnight, before midnight",
+			window:   "wed 22:00-02:00",
+			value:    time.Date(2021, 02, 17, 23, 00, 00, 00, time.UTC),
+			expected: true,
+		},
+		{
+			name:     "over midnight, after midnight",
+			window:   "wed 22:00-02:00",
+			value:    time.Date(2021, 02, 18, 1, 00, 00, 00, time.UTC),
+			expected: true,
+		},
+		{
+			name:     "over midnight, after midnight on start day",
+			window:   "wed 22:00-02:00",
+			value:    time.Date(2021, 02, 17, 1, 00, 00, 00, time.UTC),
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := scheduler.Window{}
+			if err := w.FromString(test.window); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got := w.Contains(test.value); got != test.expected {
+				t.Errorf("got: %t, expected: %t", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateNextFromWindows(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     time.Time
+		interval time.Duration
+		windows  []string
+		days     []time.Weekday
+		hours    []int
+		timezone string
+		expected time.Time
+	}{
+		{
+			name:     "within window",
+			from:     time.Date(2021, 02, 17, 9, 40, 00, 00, time.UTC),
+			interval: 10 * time.Minute,
+			windows:  []string{"mon-fri 09:30-11:15", "sat 10:00-14:00"},
+			expected: time.Date(2021, 02, 17, 9, 50, 00, 00, time.UTC),
+		},
+		{
+			name:     "before first window",
+			from:     time.Date(2021, 02, 17, 8, 00, 00, 00, time.UTC),
+			interval: 10 * time.Minute,
+			windows:  []string{"mon-fri 09:30-11:15", "mon-fri 13:00-16:45"},
+			expected: time.Date(2021, 02, 17, 9, 30, 00, 00, time.UTC),
+		},
+		{
+			name:     "between windows",
+			from:     time.Date(2021, 02, 17, 11, 10, 00, 00, time.UTC),
+			interval: 10 * time.Minute,
+			windows:  []string{"mon-fri 09:30-11:15", "mon-fri 13:00-16:45"},
+			expected: time.Date(2021, 02, 17, 13, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "after last window on friday",
+			from:     time.Date(2021, 02, 19, 16, 40, 00, 00, time.UTC),
+			interval: 10 * time.Minute,
+			windows:  []string{"mon-fri 09:30-11:15", "sat 10:00-14:00"},
+			expected: time.Date(2021, 02, 20, 10, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "after saturday window",
+			from:     time.Date(2021, 02, 20, 14, 00, 00, 00, time.UTC),
+			interval: 10 * time.Minute,
+			windows:  []string{"mon-fri 09:30-11:15", "sat 10:00-14:00"},
+			expected: time.Date(2021, 02, 22, 9, 30, 00, 00, time.UTC),
+		},
+		{
+			name:     "window crossing midnight",
+			from:     time.Date(2021, 02, 19, 23, 50, 00, 00, time.UTC),
+			interval: 30 * time.Minute,
+			windows:  []string{"fri 23:00-01:00"},
+			expected: time.Date(2021, 02, 20, 0, 20, 00, 00, time.UTC),
+		},
+		{
+			name:     "after window crossing midnight",
+			from:     time.Date(2021, 02, 20, 0, 50, 00, 00, time.UTC),
+			interval: 30 * time.Minute,
+			windows:  []string{"fri 23:00-01:00"},
+			expected: time.Date(2021, 02, 26, 23, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "windows combined with days",
+			from:     time.Date(2021, 02, 17, 11, 10, 00, 00, time.UTC),
+			interval: 10 * time.Minute,
+			windows:  []string{"09:30-11:15"},
+			days:     []time.Weekday{time.Monday, time.Friday},
+			expected: time.Date(2021, 02, 19, 9, 30, 00, 00, time.UTC),
+		},
+		{
+			name:     "windows combined with hours",
+			from:     time.Date(2021, 02, 17, 8, 00, 00, 00, time.UTC),
+			interval: 10 * time.Minute,
+			windows:  []string{"09:30-11:15"},
+			hours:    []int{10, 11},
+			expected: time.Date(2021, 02, 17, 10, 00, 00, 00, time.UTC),
+		},
+		{
+			name:     "windows in timezone",
+			from:     time.Date(2021, 02, 17, 8, 00, 00, 00, time.UTC),
+			interval: 10 * time.Minute,
+			windows:  []string{"mon-fri 09:30-11:15"},
+			timezone: "Europe/Oslo",
+			expected: time.Date(2021, 02, 17, 8, 30, 00, 00, time.UTC),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := scheduler.NewScheduler(test.interval, nil, test.hours, test.days)
+			for _, str := range test.windows {
+				w := scheduler.Window{}
+				if err := w.FromString(str); err != nil {
+					t.Fatalf("unexpected err: %v", err)
+				}
+				s.Windows = append(s.Windows, w)
+			}
+			if err := s.SetTimezone(test.timezone); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			to := s.CalculateNextFrom(test.from)
+			if !to.Equal(test.expected) {
+				t.Errorf("got: %s, expected: %s", to.UTC().String(), test.expected.String())
+			}
+			if !s.IsWithinSchedule(to) {
+				t.Errorf("%s is not within schedule", to.String())
+			}
+		})
+	}
+}
+
+func TestScheduler_UnmarshalYAMLWindows(t *testing.T) {
+	data := `
+interval: 60s
+windows:
+  - "mon-fri 09:30-11:15"
+  - "sat 10:00-14:00"
+`
+	s := &scheduler.Scheduler{}
+	if err := yaml.Unmarshal([]byte(data), s); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	expected := "60;0;;;;;1,2,3,4,5 09:30-11:15|6 10:00-14:00"
+	if s.String() != expected {
+		t.Errorf("got '%s', expected '%s'", s.String(), expected)
+	}
+
+	fromString, err := scheduler.NewSchedulerFromString(expected)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if fromString.String() != expected {
+		t.Errorf("got '%s' from string, expected '%s'", fromString.String(), expected)
+	}
+}
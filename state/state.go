@@ -0,0 +1,107 @@
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MonitorState is the part of a monitor's status which survives restarts.
+type MonitorState struct {
+	LastCheckedAt time.Time `json:"last_checked_at"`
+	NextCheckAt   time.Time `json:"next_check_at"`
+	LastSeenState bool      `json:"last_seen_state"`
+}
+
+// Store keeps the state of all monitors by name and persists it to a JSON
+// file.
+type Store struct {
+	filename string
+
+	mu     sync.Mutex
+	states map[string]MonitorState
+}
+
+func NewStore(filename string) *Store {
+	return &Store{
+		filename: filename,
+		states:   make(map[string]MonitorState),
+	}
+}
+
+// Load reads the state file. A missing file is not an error, it just means
+// there is no state to resume from.
+func (s *Store) Load() error {
+	data, err := ioutil.ReadFile(s.filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	states := make(map[string]MonitorState)
+	if err := json.Unmarshal(data, &states); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states = states
+
+	return nil
+}
+
+func (s *Store) Get(name string) (MonitorState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.states[name]
+
+	return st, ok
+}
+
+func (s *Store) Set(name string, st MonitorState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[name] = st
+}
+
+// Save writes the state file through a temporary file, so a crash while
+// writing never leaves a truncated state file behind.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(s.states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.filename), filepath.Base(s.filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.filename)
+}
+
+func (s *Store) Delete(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, name)
+}
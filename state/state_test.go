@@ -0,0 +1,69 @@
+package state_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"website-monitor/state"
+)
+
+func TestStore_SaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "state.json")
+
+	s := state.NewStore(filename)
+	if err := s.Load(); err != nil {
+		t.Fatalf("missing file; got err %v, expected nil", err)
+	}
+	if _, ok := s.Get("monitor"); ok {
+		t.Error("expected no state before saving")
+	}
+
+	expected := state.MonitorState{
+		LastCheckedAt: time.Date(2021, 02, 17, 10, 00, 00, 00, time.UTC),
+		NextCheckAt:   time.Date(2021, 02, 17, 10, 05, 00, 00, time.UTC),
+		LastSeenState: true,
+	}
+	s.Set("monitor", expected)
+	if err := s.Save(); err != nil {
+		t.Fatalf("got err %v, expected nil", err)
+	}
+
+	loaded := state.NewStore(filename)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("got err %v, expected nil", err)
+	}
+	got, ok := loaded.Get("monitor")
+	if !ok {
+		t.Fatal("expected saved state to be loaded")
+	}
+	if !got.LastCheckedAt.Equal(expected.LastCheckedAt) || !got.NextCheckAt.Equal(expected.NextCheckAt) || got.LastSeenState != expected.LastSeenState {
+		t.Errorf("got %+v, expected %+v", got, expected)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("got %d files in state dir, expected only the state file", len(files))
+	}
+}
+
+func TestStore_LoadInvalid(t *testing.T) {
+	f, err := ioutil.TempFile("", "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	_, _ = f.WriteString("not json")
+	_ = f.Close()
+
+	if err := state.NewStore(f.Name()).Load(); err == nil {
+		t.Error("got nil, expected err for invalid state file")
+	}
+}